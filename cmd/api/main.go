@@ -12,10 +12,18 @@ import (
 
 	"github.com/SteepTaq/todo_project/internal/api/client"
 	"github.com/SteepTaq/todo_project/internal/api/config"
+	"github.com/SteepTaq/todo_project/internal/api/events"
 	"github.com/SteepTaq/todo_project/internal/api/handler"
 	"github.com/SteepTaq/todo_project/internal/api/kafka"
+	"github.com/SteepTaq/todo_project/internal/api/ratelimit"
+	"github.com/SteepTaq/todo_project/pkg/conf"
 	ctxLog "github.com/SteepTaq/todo_project/pkg/context"
+	"github.com/SteepTaq/todo_project/pkg/health"
 	"github.com/SteepTaq/todo_project/pkg/logger"
+	"github.com/SteepTaq/todo_project/pkg/metrics"
+	pkgmiddleware "github.com/SteepTaq/todo_project/pkg/middleware"
+	"github.com/SteepTaq/todo_project/pkg/tlsconfig"
+	"github.com/SteepTaq/todo_project/pkg/tracing"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -23,10 +31,18 @@ import (
 
 func main() {
 	// Загрузка конфигурации
-	cfg := config.LoadConfig()
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		slog.Error("invalid configuration", "error", err)
+		os.Exit(1)
+	}
 
 	// Инициализация логгера
-	log := logger.Setup(cfg.Logger.Level)
+	log, err := logger.Setup(cfg.Logger)
+	if err != nil {
+		slog.Error("failed to set up logger", "error", err)
+		os.Exit(1)
+	}
 	slog.SetDefault(log)
 	log.Info("Configuration loaded",
 		"http_port", cfg.HTTP.Port,
@@ -38,6 +54,14 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	// Трассировка
+	shutdownTracing, err := tracing.Setup(ctx, "todo-api", cfg.Tracing)
+	if err != nil {
+		log.Error("failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+	defer shutdownTracing(context.Background())
+
 	// Запуск приложения
 	if err := App(ctx, cfg, log); err != nil {
 		log.Error("application failed", "error", err)
@@ -47,49 +71,102 @@ func main() {
 
 func App(ctx context.Context, cfg *config.Config, log *slog.Logger) error {
 	// Инициализация gRPC клиента (заглушка, реализация в client/grpc.go)
-	dbClient, err := client.NewDBClient(cfg.GRPC.Target, cfg.GRPC.Timeout, log)
+	dbClient, err := client.NewDBClient(cfg.GRPC, log)
 	if err != nil {
 		return fmt.Errorf("failed to create gRPC client: %w", err)
 	}
 	defer dbClient.Close()
 
+	// Адрес клиента из X-Forwarded-For берется только от доверенных прокси
+	trustedProxies, err := pkgmiddleware.ParseTrustedProxies(cfg.HTTP.TrustedProxies)
+	if err != nil {
+		return fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+	realIP := pkgmiddleware.RealIP(trustedProxies)
+
 	// Создание HTTP роутера
 	r := chi.NewRouter()
 
 	// Middleware
+	r.Use(tracing.HTTPMiddleware)
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
+	r.Use(realIP)
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(30 * time.Second))
-	//r.Use(middleware.GetStructuredLogger(log))
+	r.Use(metrics.HTTPMiddleware)
 	r.Use(func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			requestLogger := log.With(
-				"request_id", middleware.GetReqID(r.Context()),
+			info := ctxLog.RequestInfo{
+				RequestID: middleware.GetReqID(r.Context()),
+				UserID:    r.Header.Get("X-User-ID"),
+				TenantID:  r.Header.Get("X-Tenant-ID"),
+			}
+			requestLogger := log.With(info.Attrs()...).With(
 				"method", r.Method,
 				"path", r.URL.Path,
 			)
-			ctx := ctxLog.WithLogger(r.Context(), requestLogger)
+			ctx := ctxLog.WithRequestInfo(r.Context(), info)
+			ctx = ctxLog.WithLogger(ctx, requestLogger)
 
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	})
+	r.Use(pkgmiddleware.AccessLog(log, cfg.AccessLog))
 
 	// Создать Kafka-продюсер
 	producer := kafka.NewProducer(cfg.Kafka.Brokers, cfg.Kafka.Topic)
 	defer producer.Close()
 
+	// Рассылка изменений задач подписчикам SSE
+	var broadcaster *events.Broadcaster
+	if cfg.Events.Enabled {
+		broadcaster = events.NewBroadcaster(cfg.Events.HistorySize, cfg.Events.SubscriberBuffer)
+	}
+
+	// Ограничение частоты запросов и дневные квоты
+	limiter := ratelimit.New(cfg.RateLimit, log)
+	defer limiter.Close()
+
+	// Лимиты, уровень логирования и таймауты вызовов перечитываются из config.yml на лету
+	holder := conf.NewHolder(cfg)
+	holder.Subscribe(func(prev, cfg *config.Config) {
+		if cfg.Logger.Level != prev.Logger.Level {
+			logger.SetLevel(cfg.Logger.Level)
+		}
+		limiter.SetConfig(cfg.RateLimit)
+		dbClient.Reconfigure(cfg.GRPC)
+	})
+	if err := config.Watch(holder, log); err != nil {
+		log.Warn("config hot reload is disabled", "error", err)
+	}
+
 	// Инициализация и регистрация обработчиков
-	todoHandler := handler.NewTodoHandler(cfg, dbClient, producer)
-	todoHandler.RegisterRoutes(r)
-
-	// Health check
-	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-		log.Info("ok")
+	todoHandler := handler.NewTodoHandler(cfg, dbClient, producer, broadcaster)
+	r.Group(func(r chi.Router) {
+		r.Use(pkgmiddleware.Unless(handler.StreamRequested, middleware.Timeout(30*time.Second)))
+		r.Use(limiter.Middleware("tasks"))
+		todoHandler.RegisterRoutes(r, limiter.DailyQuota())
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(limiter.Middleware("stream"))
+		todoHandler.RegisterStreamRoutes(r)
 	})
 
+	// Health check: /livez - процесс жив, /readyz - доступны db сервис и Kafka
+	checker := health.NewChecker(cfg.Health.CacheTTL,
+		health.Check{Name: "db_service", Timeout: cfg.Health.Timeout, Func: dbClient.Ping},
+		health.Check{Name: "kafka", Timeout: cfg.Health.Timeout, Func: producer.Ping},
+		health.Check{Name: "db_cache", Timeout: cfg.Health.Timeout, Func: dbClient.PingCache, Optional: true},
+	)
+	r.Handle("/livez", health.LiveHandler())
+	r.Handle("/health", health.LiveHandler())
+	r.Handle("/readyz", checker.ReadyHandler())
+
+	// Метрики Prometheus
+	r.Handle("/metrics", metrics.Handler())
+
+	// Смена уровня логирования без перезапуска
+	r.Handle("/admin/log-level", logger.LevelHandler(cfg.Admin.Token))
+
 	// HTTP сервер
 	server := &http.Server{
 		Addr:         ":" + cfg.HTTP.Port,
@@ -97,12 +174,29 @@ func App(ctx context.Context, cfg *config.Config, log *slog.Logger) error {
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 	}
+	server.RegisterOnShutdown(todoHandler.CloseStreams)
+
+	if cfg.HTTP.TLS.Enabled {
+		reloader, err := tlsconfig.NewReloader(cfg.HTTP.TLS, log)
+		if err != nil {
+			return fmt.Errorf("failed to load TLS config: %w", err)
+		}
+		go reloader.Run(ctx)
+		server.TLSConfig = reloader.ServerConfig()
+	}
 
 	// Запуск сервера в горутине
 	serverErr := make(chan error, 1)
 	go func() {
-		log.Info("starting HTTP server", "port", cfg.HTTP.Port)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Info("starting HTTP server", "port", cfg.HTTP.Port, "tls", cfg.HTTP.TLS.Enabled)
+		var err error
+		if cfg.HTTP.TLS.Enabled {
+			// Сертификат берется из server.TLSConfig
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			serverErr <- err
 		}
 	}()
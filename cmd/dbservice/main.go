@@ -2,34 +2,73 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log/slog"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/SteepTaq/todo_project/internal/dbservice/config"
+	"github.com/SteepTaq/todo_project/internal/dbservice/migrations"
 	"github.com/SteepTaq/todo_project/internal/dbservice/repository"
 	"github.com/SteepTaq/todo_project/internal/dbservice/server"
 	"github.com/SteepTaq/todo_project/internal/dbservice/service"
+	"github.com/SteepTaq/todo_project/pkg/conf"
 	"github.com/SteepTaq/todo_project/pkg/logger"
+	"github.com/SteepTaq/todo_project/pkg/metrics"
+	"github.com/SteepTaq/todo_project/pkg/middleware"
+	"github.com/SteepTaq/todo_project/pkg/tlsconfig"
+	"github.com/SteepTaq/todo_project/pkg/tracing"
 	todov1 "github.com/SteepTaq/todo_project/pkg/proto/gen/todo"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
 )
 
 func main() {
+	storage := flag.String("storage", "", "Storage backend instead of storage.backend from config (postgres, sqlite, memory)")
+	flag.Parse()
+
 	// Загрузка конфигурации
-	cfg := config.LoadConfig()
+	cfg, err := config.LoadConfig()
+	if err == nil && *storage != "" {
+		cfg.Storage.Backend = *storage
+		err = cfg.Validate()
+	}
+	if err != nil {
+		slog.Error("invalid configuration", "error", err)
+		os.Exit(1)
+	}
 
 	// Инициализация логгера
-	log := logger.Setup(cfg.Logger.Level)
+	log, err := logger.Setup(cfg.Logger)
+	if err != nil {
+		slog.Error("failed to set up logger", "error", err)
+		os.Exit(1)
+	}
 	slog.SetDefault(log)
 
 	// Создание контекста для graceful shutdown
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	// Трассировка
+	shutdownTracing, err := tracing.Setup(ctx, "todo-db-service", cfg.Tracing)
+	if err != nil {
+		log.Error("failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+	defer shutdownTracing(context.Background())
+
 	// Запуск приложения
 	if err := app(ctx, cfg, log); err != nil {
 		log.Error("application failed", "error", err)
@@ -38,51 +77,106 @@ func main() {
 }
 
 func app(ctx context.Context, cfg *config.Config, log *slog.Logger) error {
-	// Формируем DSN для Postgres
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		cfg.Postgres.Host,
-		cfg.Postgres.Port,
-		cfg.Postgres.User,
-		cfg.Postgres.Password,
-		cfg.Postgres.DBName,
-		cfg.Postgres.SSLMode,
-	)
 	// Инициализация репозиториев
-	pgRepo, err := repository.NewPostgresRepo(
-		dsn,
-		cfg.Postgres.MaxConns,
-		cfg.Postgres.MaxIdleTime,
-		log,
-	)
+	storage, err := openStorage(cfg, log)
 	if err != nil {
-		return fmt.Errorf("failed to create Postgres repo: %w", err)
+		return err
 	}
-	defer pgRepo.Close()
+	defer storage.Close()
 
-	redisAddr := fmt.Sprintf("%s:%s", cfg.Redis.Host, cfg.Redis.Port)
-	redisRepo, err := repository.NewRedisRepo(
-		redisAddr,
-		cfg.Redis.Password,
-		cfg.Redis.DB,
-		cfg.Redis.CacheTTL,
-		log,
-	)
+	cachePolicy := func(cfg *config.Config) repository.CachePolicy {
+		return repository.CachePolicy{
+			TTL:              cfg.Redis.CacheTTL,
+			NegativeTTL:      cfg.Redis.NegativeTTL,
+			EarlyRefreshBeta: cfg.Redis.EarlyRefreshBeta,
+		}
+	}
+	remoteCache, setCachePolicy, closeCache, err := openCache(ctx, cfg, cachePolicy(cfg), log)
 	if err != nil {
-		return fmt.Errorf("failed to create Redis repo: %w", err)
+		return err
 	}
-	defer redisRepo.Close()
+	defer closeCache()
+
+	// Уровень логирования и ttl кеша перечитываются из config.yml на лету
+	holder := conf.NewHolder(cfg)
+	holder.Subscribe(func(prev, cfg *config.Config) {
+		if cfg.Logger.Level != prev.Logger.Level {
+			logger.SetLevel(cfg.Logger.Level)
+		}
+		setCachePolicy(cachePolicy(cfg))
+	})
+	if err := config.Watch(holder, log); err != nil {
+		log.Warn("config hot reload is disabled", "error", err)
+	}
+
+	// Без Redis сервис работает напрямую с хранилищем
+	cache := repository.NewBreakerCache(remoteCache, cfg.Redis.Breaker.FailureThreshold, cfg.Redis.Timeout, log)
+	go cache.Run(ctx, cfg.Redis.Breaker.RetryInterval)
 
 	// Создание сервиса с кеширующим слоем
-	taskService := service.NewTaskService(pgRepo, redisRepo, log)
+	taskService := service.NewTaskService(storage, cache, log)
+
+	// Метрики
+	prometheus.MustRegister(service.NewStatusCollector(storage, 5*time.Second))
+	if pgRepo, ok := storage.(*repository.PostgresRepo); ok {
+		prometheus.MustRegister(metrics.NewPgxPoolCollector(pgRepo.Stat))
+	}
+	adminMux := http.NewServeMux()
+	adminMux.Handle("/admin/log-level", logger.LevelHandler(cfg.Admin.Token))
+	metrics.ServeAdmin(ctx, ":"+cfg.Admin.Port, adminMux, log)
+
+	// TLS для соединений с API, сертификаты перечитываются при изменении файлов
+	creds := insecure.NewCredentials()
+	if cfg.GRPC.TLS.Enabled {
+		reloader, err := tlsconfig.NewReloader(cfg.GRPC.TLS, log)
+		if err != nil {
+			return fmt.Errorf("failed to load TLS config: %w", err)
+		}
+		go reloader.Run(ctx)
+		creds = credentials.NewTLS(reloader.ServerConfig())
+	}
+
+	// Сверх лимита одновременных вызовов сначала отклоняются выгрузки и записи
+	limiter := server.NewConcurrencyLimiter(cfg.GRPC.Concurrency)
 
 	// Создание gRPC сервера
 	grpcServer := grpc.NewServer(
+		grpc.Creds(creds),
 		grpc.ConnectionTimeout(cfg.GRPC.Timeout),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             cfg.GRPC.Keepalive.MinTime,
+			PermitWithoutStream: cfg.GRPC.Keepalive.PermitWithoutStream,
+		}),
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(
+			middleware.UnaryServerRequestInfo(log),
+			middleware.UnaryServerAccessLog(log, cfg.AccessLog),
+			metrics.UnaryServerInterceptor(),
+			limiter.UnaryServerInterceptor(),
+		),
+		grpc.ChainStreamInterceptor(
+			middleware.StreamServerRequestInfo(log),
+			metrics.StreamServerInterceptor(),
+			limiter.StreamServerInterceptor(),
+		),
 	)
 
 	// Регистрация сервиса
 	todov1.RegisterTodoServiceServer(grpcServer, server.NewGRPCServer(taskService))
 
+	// grpc.health.v1: кеш не критичен, без него сервис работает напрямую с хранилищем.
+	// Проверка кеша называется "redis" и для кеша в памяти: по этому имени его проверяет API
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	go server.RunHealthChecks(ctx, healthServer, cfg.Health.Interval, cfg.Health.Timeout,
+		[]server.HealthCheck{
+			{Name: cfg.Storage.Backend, Critical: true, Ping: storage.Ping},
+			{Name: "redis", Ping: cache.Ping},
+		},
+		[]string{todov1.TodoService_ServiceDesc.ServiceName},
+		log,
+	)
+
 	// Запуск gRPC сервера
 	listener, err := net.Listen("tcp", cfg.GRPC.Target)
 	if err != nil {
@@ -98,11 +192,104 @@ func app(ctx context.Context, cfg *config.Config, log *slog.Logger) error {
 
 	// Ожидание сигнала завершения
 	<-ctx.Done()
-	log.Info( "shutting down server")
+	log.Info("shutting down server")
+	// Клиенты перестают слать новые запросы, пока идет остановка
+	healthServer.Shutdown()
 
-	// Graceful shutdown
-	grpcServer.GracefulStop()
-	log.Info("server stopped gracefully")
+	// Graceful shutdown. Потоки WatchTasks сами не завершаются,
+	// поэтому после таймаута закрываем оставшиеся соединения принудительно
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		log.Info("server stopped gracefully")
+	case <-time.After(cfg.GRPC.ShutdownTimeout):
+		grpcServer.Stop()
+		log.Warn("graceful shutdown timed out, server stopped")
+	}
 
 	return nil
 }
+
+// taskStorage - хранилище задач, выбранное в storage.backend
+type taskStorage interface {
+	service.TaskRepository
+	Ping(ctx context.Context) error
+	Close()
+}
+
+func openStorage(cfg *config.Config, log *slog.Logger) (taskStorage, error) {
+	switch cfg.Storage.Backend {
+	case config.BackendMemory:
+		log.Warn("using in-memory storage, tasks are lost on restart")
+		return repository.NewMemoryRepo(log), nil
+	case config.BackendSQLite:
+		repo, err := repository.NewSQLiteRepo(repository.SQLiteConfig{
+			Path:        cfg.SQLite.Path,
+			BusyTimeout: cfg.SQLite.BusyTimeout,
+		}, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create SQLite repo: %w", err)
+		}
+		log.Info("using SQLite storage", "path", cfg.SQLite.Path)
+		return repo, nil
+	default:
+		if cfg.Migrations.Auto {
+			if err := migrations.Postgres.Up(cfg.PostgresURL(), cfg.Migrations.LockTimeout, log); err != nil {
+				return nil, err
+			}
+		}
+		// Формируем DSN для Postgres
+		dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+			cfg.Postgres.Host,
+			cfg.Postgres.Port,
+			cfg.Postgres.User,
+			cfg.Postgres.Password,
+			cfg.Postgres.DBName,
+			cfg.Postgres.SSLMode,
+		)
+		repo, err := repository.NewPostgresRepo(
+			dsn,
+			cfg.Postgres.MaxConns,
+			cfg.Postgres.MaxIdleTime,
+			log,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Postgres repo: %w", err)
+		}
+		return repo, nil
+	}
+}
+
+// openCache подключает Redis, а для хранилища в памяти - кеш в памяти,
+// чтобы сервис запускался без внешних зависимостей
+func openCache(ctx context.Context, cfg *config.Config, policy repository.CachePolicy, log *slog.Logger) (repository.Cache, func(repository.CachePolicy), func(), error) {
+	if cfg.Storage.Backend == config.BackendMemory {
+		cache := repository.NewMemoryCache(policy)
+		return cache, cache.SetPolicy, func() {}, nil
+	}
+
+	redisAddr := fmt.Sprintf("%s:%s", cfg.Redis.Host, cfg.Redis.Port)
+	redisRepo, err := repository.NewRedisRepo(
+		redisAddr,
+		cfg.Redis.Password,
+		cfg.Redis.DB,
+		policy,
+		cfg.Redis.Timeout,
+		log,
+	)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create Redis repo: %w", err)
+	}
+
+	var cache repository.Cache = redisRepo
+	if cfg.Redis.Local.Enabled {
+		localCache := repository.NewLocalCache(redisRepo, cfg.Redis.Local.MaxEntries, cfg.Redis.Local.TTL, log)
+		go localCache.Run(ctx)
+		cache = localCache
+	}
+	return cache, redisRepo.SetPolicy, redisRepo.Close, nil
+}
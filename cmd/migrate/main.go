@@ -1,71 +1,72 @@
 package main
 
 import (
-	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
 	"os"
+	"slices"
 	"strconv"
-	"time"
 
 	"github.com/SteepTaq/todo_project/internal/dbservice/config"
+	"github.com/SteepTaq/todo_project/internal/dbservice/migrations"
 	"github.com/SteepTaq/todo_project/pkg/logger"
 	"github.com/golang-migrate/migrate/v4"
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 func main() {
 	// Парсинг аргументов командной строки
-	action := flag.String("action", "up", "Migration action (up, down, force)")
-	steps := flag.Int("steps", 0, "Number of steps for migration")
-	version := flag.String("version", "", "Version for force migration")
+	action := flag.String("action", "up", "Migration action (up, down, goto, force, version, status, drop)")
+	steps := flag.Int("steps", 0, "Number of steps for up and down, all migrations by default")
+	version := flag.String("version", "", "Target version for goto and force")
+	dryRun := flag.Bool("dry-run", false, "Print SQL of up, down and goto without applying it")
+	confirm := flag.Bool("confirm", false, "Confirm drop of all tables")
 	flag.Parse()
 
 	// Загрузка конфигурации
-	cfg := config.LoadConfig()
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		slog.Error("invalid configuration", "error", err)
+		os.Exit(1)
+	}
 
 	// Инициализация логгера
-	log := logger.Setup(cfg.Logger.Level)
-	slog.SetDefault(log)
-
-	// Формирование DSN
-	dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
-		cfg.Postgres.User,
-		cfg.Postgres.Password,
-		cfg.Postgres.Host,
-		cfg.Postgres.Port,
-		cfg.Postgres.DBName,
-		cfg.Postgres.SSLMode,
-	)
-
-	// Подключение к БД для проверки
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	pool, err := pgxpool.New(ctx, dsn)
+	log, err := logger.Setup(cfg.Logger)
 	if err != nil {
-		log.Error("Failed to connect to database", "error", err)
+		slog.Error("failed to set up logger", "error", err)
 		os.Exit(1)
 	}
-	defer pool.Close()
+	slog.SetDefault(log)
 
-	if err := pool.Ping(ctx); err != nil {
-		log.Error("Database ping failed", "error", err)
+	// Миграции встроены в бинарник, база выбирается по storage.backend
+	var set migrations.Set
+	var databaseURL string
+	switch cfg.Storage.Backend {
+	case config.BackendPostgres:
+		set, databaseURL = migrations.Postgres, cfg.PostgresURL()
+	case config.BackendSQLite:
+		set, databaseURL = migrations.SQLite, "sqlite://"+cfg.SQLite.Path
+	default:
+		log.Error("Storage backend has no migrations", "backend", cfg.Storage.Backend)
 		os.Exit(1)
 	}
 
 	// Инициализация мигратора
-	m, err := migrate.New(
-		"file://internal/dbservice/migrations",
-		dsn,
-	)
+	m, err := set.New(databaseURL)
 	if err != nil {
 		log.Error("Failed to initialize migrator", "error", err)
 		os.Exit(1)
 	}
+	defer m.Close()
+
+	if *dryRun {
+		if err := printPlan(m, set, *action, *steps, *version); err != nil {
+			log.Error("Dry run failed", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
 
 	// Выполнение действия
 	switch *action {
@@ -82,9 +83,17 @@ func main() {
 			log.Info("Reverting migrations", "steps", *steps)
 			err = m.Steps(-*steps)
 		} else {
-			log.Info("Reverting last migration")
+			log.Info("Reverting all migrations")
 			err = m.Down()
 		}
+	case "goto":
+		v, errConv := parseVersion(*version)
+		if errConv != nil {
+			log.Error("Invalid version number", "version", *version, "error", errConv)
+			os.Exit(1)
+		}
+		log.Info("Migrating to version", "version", v)
+		err = m.Migrate(v)
 	case "force":
 		if *version == "" {
 			log.Error("Version is required for force migration")
@@ -97,6 +106,31 @@ func main() {
 		}
 		log.Info("Forcing migration version", "version", v)
 		err = m.Force(v)
+	case "version":
+		current, dirty, err := currentVersion(m)
+		if err != nil {
+			log.Error("Failed to get version", "error", err)
+			os.Exit(1)
+		}
+		if current == 0 {
+			fmt.Println("no migrations applied")
+		} else {
+			fmt.Printf("%d%s\n", current, dirtyMark(dirty))
+		}
+		return
+	case "status":
+		if err := printStatus(m, set); err != nil {
+			log.Error("Failed to get status", "error", err)
+			os.Exit(1)
+		}
+		return
+	case "drop":
+		if !*confirm {
+			log.Error("Drop removes all tables and data, run again with -confirm")
+			os.Exit(1)
+		}
+		log.Warn("Dropping all tables")
+		err = m.Drop()
 	default:
 		log.Error("Unknown action", "action", *action)
 		os.Exit(1)
@@ -104,7 +138,7 @@ func main() {
 
 	// Обработка результата
 	if err != nil {
-		if err == migrate.ErrNoChange {
+		if errors.Is(err, migrate.ErrNoChange) {
 			log.Info("No migrations applied - database is up to date")
 		} else {
 			log.Error("Migration failed", "error", err)
@@ -114,3 +148,112 @@ func main() {
 
 	log.Info("Migration completed successfully")
 }
+
+func parseVersion(s string) (uint, error) {
+	if s == "" {
+		return 0, errors.New("version is required")
+	}
+	v, err := strconv.ParseUint(s, 10, 64)
+	return uint(v), err
+}
+
+// currentVersion возвращает 0, если миграции еще не применялись
+func currentVersion(m *migrate.Migrate) (uint, bool, error) {
+	v, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	return v, dirty, err
+}
+
+func dirtyMark(dirty bool) string {
+	if dirty {
+		return " (dirty)"
+	}
+	return ""
+}
+
+// printStatus выводит все миграции с отметкой, применены ли они
+func printStatus(m *migrate.Migrate, set migrations.Set) error {
+	current, dirty, err := currentVersion(m)
+	if err != nil {
+		return err
+	}
+	list, err := set.List()
+	if err != nil {
+		return err
+	}
+	for _, mig := range list {
+		state := "pending"
+		switch {
+		case mig.Version == current && dirty:
+			state = "dirty"
+		case mig.Version <= current:
+			state = "applied"
+		}
+		fmt.Printf("%-8s %06d_%s\n", state, mig.Version, mig.Name)
+	}
+	return nil
+}
+
+// printPlan выводит SQL, который выполнили бы up, down или goto
+func printPlan(m *migrate.Migrate, set migrations.Set, action string, steps int, version string) error {
+	current, dirty, err := currentVersion(m)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("database is dirty at version %d, fix it and run force", current)
+	}
+	list, err := set.List()
+	if err != nil {
+		return err
+	}
+
+	var target uint
+	switch action {
+	case "up":
+		target = current
+		n := 0
+		for _, mig := range list {
+			if mig.Version > current && (steps <= 0 || n < steps) {
+				target = mig.Version
+				n++
+			}
+		}
+	case "down":
+		var applied []uint
+		for _, mig := range list {
+			if mig.Version <= current {
+				applied = append(applied, mig.Version)
+			}
+		}
+		// Без steps откатываются все миграции
+		if steps > 0 && steps < len(applied) {
+			target = applied[len(applied)-steps-1]
+		}
+	case "goto":
+		if target, err = parseVersion(version); err != nil {
+			return err
+		}
+		if !slices.ContainsFunc(list, func(mig migrations.Migration) bool { return mig.Version == target }) {
+			return fmt.Errorf("no migration with version %d", target)
+		}
+	default:
+		return fmt.Errorf("dry run is not supported for %q", action)
+	}
+
+	plan := migrations.Plan(list, current, target)
+	if len(plan) == 0 {
+		fmt.Printf("-- version %d, nothing to do\n", current)
+		return nil
+	}
+	for _, step := range plan {
+		direction := "down"
+		if step.Up {
+			direction = "up"
+		}
+		fmt.Printf("-- %06d_%s.%s.sql\n%s\n\n", step.Migration.Version, step.Migration.Name, direction, step.SQL())
+	}
+	return nil
+}
@@ -3,30 +3,47 @@ package main
 import (
 	"context"
 	"log"
+	"log/slog"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
+	"github.com/SteepTaq/todo_project/internal/worker/config"
+	"github.com/SteepTaq/todo_project/internal/worker/notifier"
+	"github.com/SteepTaq/todo_project/pkg/metrics"
+	"github.com/SteepTaq/todo_project/pkg/tracing"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
-func main() {
-	brokers := os.Getenv("KAFKA_BROKERS")
-	if brokers == "" {
-		brokers = "localhost:9094" // fallback для локального запуска
-	}
+var tracer = otel.Tracer("github.com/SteepTaq/todo_project/cmd/worker")
 
-	topic := os.Getenv("KAFKA_TOPIC")
-	if topic == "" {
-		topic = "events" // fallback
-	}
+var (
+	consumedMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_messages_consumed_total",
+		Help: "Messages read from Kafka by topic.",
+	}, []string{"topic"})
+
+	consumerLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_consumer_lag",
+		Help: "Messages behind the end of the partition, as reported by the reader.",
+	}, []string{"topic"})
+)
 
-	logFile := os.Getenv("LOG_FILE")
-	if logFile == "" {
-		logFile = "events.log" // fallback
+func main() {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("invalid configuration:\n%v", err)
 	}
 
-	file, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	file, err := os.OpenFile(cfg.LogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		log.Fatalf("failed to open log file: %v", err)
 	}
@@ -34,8 +51,8 @@ func main() {
 	logger := log.New(file, "", log.LstdFlags)
 
 	r := kafka.NewReader(kafka.ReaderConfig{
-		Brokers:  []string{brokers},
-		Topic:    topic,
+		Brokers:  cfg.Kafka.Brokers,
+		Topic:    cfg.Kafka.Topic,
 		MinBytes: 1,
 		MaxBytes: 10e6,
 	})
@@ -52,7 +69,27 @@ func main() {
 		cancel()
 	}()
 
-	logger.Printf("Worker started, connecting to %s, topic: %s", brokers, topic)
+	slogger := slog.New(slog.NewTextHandler(file, nil))
+
+	// Трассировка
+	shutdownTracing, err := tracing.Setup(ctx, "todo-worker", cfg.Tracing)
+	if err != nil {
+		logger.Fatalf("failed to set up tracing: %v", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	// Метрики
+	metrics.ServeAdmin(ctx, ":"+cfg.Admin.Port, nil, slogger)
+	go reportLag(ctx, r, cfg.Kafka.Topic)
+
+	// Уведомления по email
+	var n *notifier.Notifier
+	if cfg.Notifier.Enabled {
+		n = newNotifier(cfg, slogger)
+		go n.Run(ctx, cfg.Notifier.FlushEvery)
+	}
+
+	logger.Printf("Worker started, connecting to %s, topic: %s", strings.Join(cfg.Kafka.Brokers, ","), cfg.Kafka.Topic)
 	for {
 		m, err := r.ReadMessage(ctx)
 		if err != nil {
@@ -63,6 +100,69 @@ func main() {
 			logger.Printf("read error: %v", err)
 			continue
 		}
+		consumedMessages.WithLabelValues(m.Topic).Inc()
 		logger.Printf("Received: %s", string(m.Value))
+
+		handle(ctx, m, n, logger)
+	}
+}
+
+// handle обрабатывает сообщение в span, продолжающем трассу продюсера из заголовков Kafka
+func handle(ctx context.Context, m kafka.Message, n *notifier.Notifier, logger *log.Logger) {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, tracing.KafkaHeaders{Message: &m})
+	ctx, span := tracer.Start(ctx, m.Topic+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingDestinationName(m.Topic),
+		),
+	)
+	defer span.End()
+
+	if n != nil {
+		if err := n.Handle(ctx, m.Value); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			logger.Printf("notification error: %v", err)
+		}
+	}
+}
+
+func newNotifier(cfg *config.Config, log *slog.Logger) *notifier.Notifier {
+	nc := cfg.Notifier
+	sender := notifier.NewSMTPSender(
+		nc.SMTP.Host,
+		nc.SMTP.Port,
+		nc.SMTP.Username,
+		nc.SMTP.Password,
+		nc.From,
+		nc.SMTP.Timeout,
+	)
+
+	prefs := make([]notifier.Preference, 0, len(nc.Recipients))
+	for _, r := range nc.Recipients {
+		prefs = append(prefs, notifier.Preference{
+			Email:  r.Email,
+			Name:   r.Name,
+			Events: r.Events,
+			Digest: notifier.DigestMode(r.Digest),
+		})
+	}
+
+	return notifier.New(sender, prefs, nc.BaseURL, log)
+}
+
+// reportLag обновляет отставание консьюмера раз в 15 секунд
+func reportLag(ctx context.Context, r *kafka.Reader, topic string) {
+	ticker := time.NewTicker(15 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			consumerLag.WithLabelValues(topic).Set(float64(r.Stats().Lag))
+		}
 	}
 }
@@ -0,0 +1,153 @@
+package board
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+)
+
+// Presence - что пользователь сейчас делает на доске
+type Presence struct {
+	User   string `json:"user"`
+	TaskID string `json:"task_id,omitempty"`
+	State  string `json:"state"`
+}
+
+type Client struct {
+	C <-chan []byte
+
+	board    string
+	send     chan []byte
+	presence Presence
+}
+
+func (c *Client) Board() string {
+	return c.board
+}
+
+func (c *Client) User() string {
+	return c.presence.User
+}
+
+// Hub хранит подключения к доскам и их присутствие.
+// Отправка никогда не блокирует: клиент с переполненным буфером отключается.
+type Hub struct {
+	mu     sync.Mutex
+	boards map[string]map[*Client]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		boards: make(map[string]map[*Client]struct{}),
+	}
+}
+
+func (h *Hub) Join(board, user string, sendBuffer int) *Client {
+	send := make(chan []byte, sendBuffer)
+	c := &Client{
+		C:        send,
+		board:    board,
+		send:     send,
+		presence: Presence{User: user, State: "viewing"},
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.boards[board] == nil {
+		h.boards[board] = make(map[*Client]struct{})
+	}
+	h.boards[board][c] = struct{}{}
+	h.broadcastPresence(board)
+
+	return c
+}
+
+func (h *Hub) Leave(c *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.remove(c) {
+		h.broadcastPresence(c.board)
+	}
+}
+
+func (h *Hub) SetPresence(c *Client, taskID, state string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.boards[c.board][c]; !ok {
+		return
+	}
+	c.presence.TaskID = taskID
+	c.presence.State = state
+	h.broadcastPresence(c.board)
+}
+
+// Send отправляет сообщение одному клиенту. Возвращает false, если клиент отключен.
+func (h *Hub) Send(c *Client, msg []byte) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.trySend(c, msg)
+}
+
+func (h *Hub) Presence(board string) []Presence {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.presence(board)
+}
+
+func (h *Hub) presence(board string) []Presence {
+	users := make([]Presence, 0, len(h.boards[board]))
+	for c := range h.boards[board] {
+		users = append(users, c.presence)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].User < users[j].User })
+	return users
+}
+
+func (h *Hub) broadcastPresence(board string) {
+	msg, err := json.Marshal(struct {
+		Type  string     `json:"type"`
+		Board string     `json:"board"`
+		Users []Presence `json:"users"`
+	}{"presence", board, h.presence(board)})
+	if err != nil {
+		return
+	}
+
+	for c := range h.boards[board] {
+		h.trySend(c, msg)
+	}
+}
+
+func (h *Hub) trySend(c *Client, msg []byte) bool {
+	if _, ok := h.boards[c.board][c]; !ok {
+		return false
+	}
+	select {
+	case c.send <- msg:
+		return true
+	default:
+		h.remove(c)
+		return false
+	}
+}
+
+func (h *Hub) remove(c *Client) bool {
+	clients, ok := h.boards[c.board]
+	if !ok {
+		return false
+	}
+	if _, ok := clients[c]; !ok {
+		return false
+	}
+	delete(clients, c)
+	close(c.send)
+	if len(clients) == 0 {
+		delete(h.boards, c.board)
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/balancer/leastrequest"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+)
+
+type Balancing struct {
+	// round_robin, least_request или pick_first
+	Policy string `mapstructure:"policy"`
+
+	Keepalive struct {
+		Time                time.Duration `mapstructure:"time"`
+		Timeout             time.Duration `mapstructure:"timeout"`
+		PermitWithoutStream bool          `mapstructure:"permit_without_stream"`
+	} `mapstructure:"keepalive"`
+
+	// Реплики, чья доля успешных ответов заметно ниже, чем у остальных,
+	// временно исключаются из балансировки. Доступно только в сборке с -tags outlier.
+	OutlierDetection struct {
+		Enabled            bool          `mapstructure:"enabled"`
+		Interval           time.Duration `mapstructure:"interval"`
+		BaseEjectionTime   time.Duration `mapstructure:"base_ejection_time"`
+		MaxEjectionPercent uint32        `mapstructure:"max_ejection_percent"`
+		// Отклонение от средней доли успехов в тысячных долях сигмы: 1900 = 1.9
+		StdevFactor   uint32 `mapstructure:"stdev_factor"`
+		MinimumHosts  uint32 `mapstructure:"minimum_hosts"`
+		RequestVolume uint32 `mapstructure:"request_volume"`
+	} `mapstructure:"outlier_detection"`
+}
+
+// dialTarget возвращает адрес для grpc.NewClient и опции подключения к репликам.
+// Если задан Targets, адреса берутся из конфига, иначе Target разрешается
+// обычным резолвером gRPC, например dns:///db-service:50051.
+func (cfg Config) dialTarget() (string, []grpc.DialOption, error) {
+	serviceConfig, err := cfg.Balancing.serviceConfig()
+	if err != nil {
+		return "", nil, err
+	}
+	opts := []grpc.DialOption{grpc.WithDefaultServiceConfig(serviceConfig)}
+
+	if ka := cfg.Balancing.Keepalive; ka.Time > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                ka.Time,
+			Timeout:             ka.Timeout,
+			PermitWithoutStream: ka.PermitWithoutStream,
+		}))
+	}
+
+	if len(cfg.Targets) == 0 {
+		return cfg.Target, opts, nil
+	}
+
+	r := manual.NewBuilderWithScheme("static")
+	addrs := make([]resolver.Address, 0, len(cfg.Targets))
+	for _, addr := range cfg.Targets {
+		addrs = append(addrs, resolver.Address{Addr: addr})
+	}
+	r.InitialState(resolver.State{Addresses: addrs})
+
+	return r.Scheme() + ":///db-service", append(opts, grpc.WithResolvers(r)), nil
+}
+
+func (b Balancing) serviceConfig() (string, error) {
+	var policy map[string]any
+	switch b.Policy {
+	case "", "round_robin":
+		policy = map[string]any{"round_robin": struct{}{}}
+	case "least_request":
+		policy = map[string]any{"least_request_experimental": map[string]any{"choiceCount": 2}}
+	case "pick_first":
+		policy = map[string]any{"pick_first": struct{}{}}
+	default:
+		return "", fmt.Errorf("unknown load balancing policy %q", b.Policy)
+	}
+
+	if od := b.OutlierDetection; od.Enabled {
+		if !outlierDetectionAvailable {
+			return "", fmt.Errorf("outlier detection requires building with -tags outlier")
+		}
+		policy = map[string]any{"outlier_detection_experimental": map[string]any{
+			"interval":           od.Interval.String(),
+			"baseEjectionTime":   od.BaseEjectionTime.String(),
+			"maxEjectionPercent": od.MaxEjectionPercent,
+			"successRateEjection": map[string]any{
+				"stdevFactor":   od.StdevFactor,
+				"minimumHosts":  od.MinimumHosts,
+				"requestVolume": od.RequestVolume,
+			},
+			"childPolicy": []any{policy},
+		}}
+	}
+
+	data, err := json.Marshal(map[string]any{"loadBalancingConfig": []any{policy}})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,5 @@
+//go:build !outlier
+
+package client
+
+const outlierDetectionAvailable = false
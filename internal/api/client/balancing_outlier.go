@@ -0,0 +1,10 @@
+//go:build outlier
+
+package client
+
+// Регистрирует балансировщик outlier_detection_experimental. Вместе с ним в бинарник
+// попадает весь стек xDS (около 18 МБ), а резолвер xds:/// и xDS credentials
+// регистрируются глобально, поэтому импорт вынесен за тег сборки outlier.
+import _ "google.golang.org/grpc/xds"
+
+const outlierDetectionAvailable = true
@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	pb "github.com/SteepTaq/todo_project/pkg/proto/gen/todo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestStaticTargetsBalancing(t *testing.T) {
+	for _, policy := range []string{"round_robin", "least_request"} {
+		t.Run(policy, func(t *testing.T) {
+			cfg := Config{Timeout: 5 * time.Second}
+			cfg.Balancing.Policy = policy
+			cfg.Balancing.OutlierDetection.Enabled = outlierDetectionAvailable
+			cfg.Balancing.OutlierDetection.Interval = time.Second
+			cfg.Balancing.OutlierDetection.BaseEjectionTime = time.Second
+
+			servers := []*flakyServer{{}, {}}
+			for _, srv := range servers {
+				lis, err := net.Listen("tcp", "127.0.0.1:0")
+				require.NoError(t, err)
+				s := grpc.NewServer()
+				pb.RegisterTodoServiceServer(s, srv)
+				go s.Serve(lis)
+				t.Cleanup(s.Stop)
+				cfg.Targets = append(cfg.Targets, lis.Addr().String())
+			}
+
+			c, err := NewDBClient(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			require.NoError(t, err)
+			defer c.Close()
+
+			for range 20 {
+				_, err := c.GetTaskById(context.Background(), "1")
+				require.NoError(t, err)
+			}
+			if policy == "round_robin" {
+				for _, srv := range servers {
+					assert.Positive(t, srv.calls.Load())
+				}
+			}
+		})
+	}
+}
@@ -3,40 +3,122 @@ package client
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/SteepTaq/todo_project/internal/api/domain"
+	"github.com/SteepTaq/todo_project/pkg/metrics"
+	"github.com/SteepTaq/todo_project/pkg/middleware"
 	pb "github.com/SteepTaq/todo_project/pkg/proto/gen/todo"
+	"github.com/SteepTaq/todo_project/pkg/tlsconfig"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type DBClient struct {
-	conn    *grpc.ClientConn
-	timeout time.Duration
-	client  pb.TodoServiceClient
-	logger  *slog.Logger
+	// Таймауты и hedging, их можно менять через Reconfigure
+	settings *atomic.Pointer[callSettings]
+	conn     *grpc.ClientConn
+	stop     context.CancelFunc
+	client   pb.TodoServiceClient
+	health   healthpb.HealthClient
+	logger   *slog.Logger
 }
 
-func NewDBClient(target string, timeout time.Duration, logger *slog.Logger) (*DBClient, error) {
-	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+type Config struct {
+	Target string `mapstructure:"target"`
+	// Статический список реплик db сервиса, заменяет Target
+	Targets []string `mapstructure:"targets"`
+	// Таймаут вызова по умолчанию, см. Resilience.MethodTimeouts
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	Resilience `mapstructure:",squash"`
+
+	Balancing Balancing `mapstructure:"balancing"`
+
+	TLS tlsconfig.Config `mapstructure:"tls"`
+}
+
+func NewDBClient(cfg Config, logger *slog.Logger) (*DBClient, error) {
+	settings := new(atomic.Pointer[callSettings])
+	settings.Store(newCallSettings(cfg))
+
+	unary := []grpc.UnaryClientInterceptor{
+		deadlineInterceptor(settings),
+	}
+	var stream []grpc.StreamClientInterceptor
+	if cfg.Breaker.FailureThreshold > 0 {
+		b := &breaker{threshold: cfg.Breaker.FailureThreshold, openTimeout: cfg.Breaker.OpenTimeout}
+		unary = append(unary, b.unaryInterceptor())
+		stream = append(stream, b.streamInterceptor())
+	}
+	unary = append(unary,
+		retryInterceptor(cfg.Resilience),
+		hedgingInterceptor(settings),
+		middleware.UnaryClientRequestInfo(),
+		metrics.UnaryClientInterceptor(),
+	)
+	stream = append(stream,
+		middleware.StreamClientRequestInfo(),
+		metrics.StreamClientInterceptor(),
+	)
+
+	target, opts, err := cfg.dialTarget()
 	if err != nil {
+		return nil, err
+	}
+	creds := insecure.NewCredentials()
+	ctx, stop := context.WithCancel(context.Background())
+	if cfg.TLS.Enabled {
+		reloader, err := tlsconfig.NewReloader(cfg.TLS, logger)
+		if err != nil {
+			stop()
+			return nil, fmt.Errorf("failed to load TLS config: %w", err)
+		}
+		go reloader.Run(ctx)
+		creds = credentials.NewTLS(reloader.ClientConfig())
+	}
+	opts = append(opts,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithChainUnaryInterceptor(unary...),
+		grpc.WithChainStreamInterceptor(stream...),
+	)
+
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		stop()
 		logger.Error("gRPC connection failed", "error", err, "target", target)
 		return nil, err
 	}
 	return &DBClient{
-		conn:    conn,
-		client:  pb.NewTodoServiceClient(conn),
-		timeout: timeout,
-		logger:  logger,
+		settings: settings,
+		conn:     conn,
+		stop:     stop,
+		client:   pb.NewTodoServiceClient(conn),
+		health:   healthpb.NewHealthClient(conn),
+		logger:   logger,
 	}, nil
 }
 
+// Reconfigure применяет новые таймауты вызовов и настройки hedging.
+// Остальные поля cfg требуют нового соединения и игнорируются.
+func (c *DBClient) Reconfigure(cfg Config) {
+	c.settings.Store(newCallSettings(cfg))
+}
+
 func (c *DBClient) Close() {
+	c.stop()
 	if c.conn != nil {
 		c.conn.Close()
 	}
@@ -70,6 +152,9 @@ func (c *DBClient) GetAllTasks(ctx context.Context) ([]domain.Task, error) {
 			Title:       task.Title,
 			Description: task.Description,
 			Status:      task.Status.String(),
+			Project:     task.Project,
+			Assignee:    task.Assignee,
+			DueAt:       dueAt(task.DueAt),
 			CreatedAt:   task.CreatedAt.AsTime(),
 			UpdatedAt:   task.UpdatedAt.AsTime(),
 		}
@@ -84,6 +169,76 @@ func (c *DBClient) GetAllTasks(ctx context.Context) ([]domain.Task, error) {
 	return tasks, nil
 }
 
+// StreamTasks получает все задачи порциями и передает каждую порцию в fn.
+// Общий таймаут не применяется: длительность выгрузки ограничивает ctx вызывающего.
+func (c *DBClient) StreamTasks(ctx context.Context, pageSize int, fn func([]domain.Task) error) error {
+	const method = "StreamTasks"
+	start := time.Now()
+	c.logger.DebugContext(ctx, "gRPC call started",
+		"method", method,
+		"page_size", pageSize,
+	)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := c.client.StreamTasks(ctx, &pb.StreamTasksRequest{PageSize: int32(pageSize)})
+	if err != nil {
+		grpcErr := handleGRPCError(err)
+		c.logger.ErrorContext(ctx, "gRPC call failed",
+			"method", method,
+			"error", grpcErr,
+			"duration", time.Since(start),
+		)
+		return grpcErr
+	}
+
+	total := 0
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			grpcErr := handleGRPCError(err)
+			c.logger.ErrorContext(ctx, "gRPC stream failed",
+				"method", method,
+				"error", grpcErr,
+				"received", total,
+				"duration", time.Since(start),
+			)
+			return grpcErr
+		}
+
+		tasks := make([]domain.Task, 0, len(resp.GetTasks()))
+		for _, task := range resp.GetTasks() {
+			tasks = append(tasks, domain.Task{
+				ID:          task.TaskId,
+				Title:       task.Title,
+				Description: task.Description,
+				Status:      task.Status.String(),
+				Project:     task.Project,
+				Assignee:    task.Assignee,
+				DueAt:       dueAt(task.DueAt),
+				CreatedAt:   task.CreatedAt.AsTime(),
+				UpdatedAt:   task.UpdatedAt.AsTime(),
+			})
+		}
+		if err := fn(tasks); err != nil {
+			return err
+		}
+		total += len(tasks)
+	}
+
+	c.logger.DebugContext(ctx, "gRPC call completed",
+		"method", method,
+		"count", total,
+		"duration", time.Since(start),
+	)
+
+	return nil
+}
+
 func (c *DBClient) GetTaskById(ctx context.Context, id string) (*domain.Task, error) {
 	const method = "GetTaskById"
 	start := time.Now()
@@ -109,6 +264,9 @@ func (c *DBClient) GetTaskById(ctx context.Context, id string) (*domain.Task, er
 		Title:       resp.Task.Title,
 		Description: resp.Task.Description,
 		Status:      resp.Task.Status.String(),
+		Project:     resp.Task.Project,
+		Assignee:    resp.Task.Assignee,
+		DueAt:       dueAt(resp.Task.DueAt),
 		CreatedAt:   resp.Task.CreatedAt.AsTime(),
 		UpdatedAt:   resp.Task.UpdatedAt.AsTime(),
 	}
@@ -122,20 +280,20 @@ func (c *DBClient) GetTaskById(ctx context.Context, id string) (*domain.Task, er
 	return task, nil
 }
 
-func (c *DBClient) CreateTask(ctx context.Context, title, description string) (*domain.Task, error) {
+func (c *DBClient) CreateTask(ctx context.Context, fields domain.TaskFields) (*domain.Task, error) {
 	start := time.Now()
 	const method = "CreateTask"
 	c.logger.DebugContext(ctx, "gRPC call started",
-		"method", method, "title", title)
-
-	ctx, cancel := context.WithTimeout(ctx, c.timeout)
-	defer cancel()
+		"method", method, "title", fields.Title)
 
 	req := &pb.CreateTaskRequest{
 		Task: &pb.Task{
-			Title:       title,
-			Description: description,
+			Title:       fields.Title,
+			Description: fields.Description,
 			Status:      pb.TaskStatus_TASK_STATUS_PENDING,
+			Project:     fields.Project,
+			Assignee:    fields.Assignee,
+			DueAt:       dueAtToProto(fields.DueAt),
 		},
 	}
 
@@ -154,6 +312,9 @@ func (c *DBClient) CreateTask(ctx context.Context, title, description string) (*
 		Title:       resp.Task.Title,
 		Description: resp.Task.Description,
 		Status:      resp.Task.Status.String(),
+		Project:     resp.Task.Project,
+		Assignee:    resp.Task.Assignee,
+		DueAt:       dueAt(resp.Task.DueAt),
 		CreatedAt:   resp.Task.CreatedAt.AsTime(),
 	}
 
@@ -166,15 +327,13 @@ func (c *DBClient) CreateTask(ctx context.Context, title, description string) (*
 	return task, nil
 }
 
-func (c *DBClient) UpdateTask(ctx context.Context, id, title, description string, status string) (*domain.Task, error) {
+func (c *DBClient) UpdateTask(ctx context.Context, id string, fields domain.TaskFields) (*domain.Task, error) {
 	start := time.Now()
 	const method = "UpdateTask"
 	c.logger.DebugContext(ctx, "gRPC call started",
-		"method", method, "title", title)
+		"method", method, "title", fields.Title)
 
-	ctx, cancel := context.WithTimeout(ctx, c.timeout)
-	defer cancel()
-	statuss, err := strconv.Atoi(status)
+	statuss, err := strconv.Atoi(fields.Status)
 	if err != nil {
 		return nil, err
 	}
@@ -183,9 +342,12 @@ func (c *DBClient) UpdateTask(ctx context.Context, id, title, description string
 	req := &pb.UpdateTaskRequest{
 		Task: &pb.Task{
 			TaskId:      id,
-			Title:       title,
-			Description: description,
+			Title:       fields.Title,
+			Description: fields.Description,
 			Status:      pbStatus,
+			Project:     fields.Project,
+			Assignee:    fields.Assignee,
+			DueAt:       dueAtToProto(fields.DueAt),
 		},
 	}
 
@@ -200,6 +362,9 @@ func (c *DBClient) UpdateTask(ctx context.Context, id, title, description string
 		Title:       resp.Task.Title,
 		Description: resp.Task.Description,
 		Status:      resp.Task.Status.String(),
+		Project:     resp.Task.Project,
+		Assignee:    resp.Task.Assignee,
+		DueAt:       dueAt(resp.Task.DueAt),
 		CreatedAt:   resp.Task.CreatedAt.AsTime(),
 		UpdatedAt:   resp.Task.UpdatedAt.AsTime(),
 	}
@@ -210,11 +375,65 @@ func (c *DBClient) UpdateTask(ctx context.Context, id, title, description string
 	return task, nil
 }
 
-func (c *DBClient) DeleteTask(ctx context.Context, id int32) error {
-	// TODO: Реализовать вызов gRPC
+func (c *DBClient) DeleteTask(ctx context.Context, id string) error {
+	start := time.Now()
+	const method = "DeleteTask"
+	c.logger.DebugContext(ctx, "gRPC call started",
+		"method", method, "task_id", id)
+
+	resp, err := c.client.DeleteTask(ctx, &pb.DeleteTaskRequest{TaskId: id})
+	if err != nil {
+		c.logger.ErrorContext(ctx, "gRPC call failed", "error", err, "duration", time.Since(start))
+		return handleGRPCError(err)
+	}
+
+	if !resp.Success {
+		c.logger.Warn("DB service returned unsuccessful response", "duration", time.Since(start))
+		return errors.New("failed to delete task")
+	}
+	c.logger.DebugContext(ctx, "Task deleted",
+		"method", method, "task_id", id, "duration", time.Since(start))
+
+	return nil
+}
+
+// Ping проверяет db сервис через grpc.health.v1
+func (c *DBClient) Ping(ctx context.Context) error {
+	return c.checkHealth(ctx, pb.TodoService_ServiceDesc.ServiceName)
+}
+
+// PingCache проверяет кеш db сервиса. Без кеша сервис работает, но медленнее.
+func (c *DBClient) PingCache(ctx context.Context) error {
+	return c.checkHealth(ctx, "redis")
+}
+
+func (c *DBClient) checkHealth(ctx context.Context, service string) error {
+	resp, err := c.health.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		return err
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("%s is %s", service, resp.GetStatus())
+	}
 	return nil
 }
 
+// dueAt и dueAtToProto переводят срок задачи, nil - без срока
+func dueAt(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+func dueAtToProto(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
 func handleGRPCError(err error) error {
 	if err == nil {
 		return nil
@@ -234,6 +453,8 @@ func handleGRPCError(err error) error {
 		return domain.ErrInvalidInput
 	case codes.DeadlineExceeded:
 		return domain.ErrRequestTimeout
+	case codes.ResourceExhausted:
+		return domain.ErrServiceOverloaded
 	case codes.Unavailable:
 		return domain.ErrServiceUnavailable
 	default:
@@ -0,0 +1,273 @@
+package client
+
+import (
+	"context"
+	"math/rand/v2"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "github.com/SteepTaq/todo_project/pkg/proto/gen/todo"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	clientRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_client_retries_total",
+		Help: "Repeated gRPC calls to the db service by method.",
+	}, []string{"method"})
+	clientHedges = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_client_hedged_requests_total",
+		Help: "Hedged gRPC calls to the db service by method.",
+	}, []string{"method"})
+	clientCircuitOpen = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "grpc_client_circuit_open",
+		Help: "1 if calls to the db service fail fast because the circuit breaker is open.",
+	})
+)
+
+// Идемпотентные методы: их можно повторять и дублировать
+var readMethods = map[string]bool{
+	pb.TodoService_GetTask_FullMethodName:     true,
+	pb.TodoService_GetAllTasks_FullMethodName: true,
+}
+
+type Resilience struct {
+	// Таймауты по именам методов (GetTask, CreateTask, ...), остальным - Config.Timeout
+	MethodTimeouts map[string]time.Duration `mapstructure:"method_timeouts"`
+
+	Retry struct {
+		MaxAttempts    int           `mapstructure:"max_attempts"`
+		InitialBackoff time.Duration `mapstructure:"initial_backoff"`
+		MaxBackoff     time.Duration `mapstructure:"max_backoff"`
+	} `mapstructure:"retry"`
+
+	Breaker struct {
+		FailureThreshold int           `mapstructure:"failure_threshold"`
+		OpenTimeout      time.Duration `mapstructure:"open_timeout"`
+	} `mapstructure:"breaker"`
+
+	Hedging struct {
+		Enabled bool          `mapstructure:"enabled"`
+		Delay   time.Duration `mapstructure:"delay"`
+	} `mapstructure:"hedging"`
+}
+
+// callSettings - настройки вызовов, которые меняются без пересоздания соединения
+type callSettings struct {
+	timeout        time.Duration
+	methodTimeouts map[string]time.Duration
+	hedging        bool
+	hedgingDelay   time.Duration
+}
+
+func newCallSettings(cfg Config) *callSettings {
+	// viper приводит ключи к нижнему регистру
+	timeouts := make(map[string]time.Duration, len(cfg.MethodTimeouts))
+	for name, d := range cfg.MethodTimeouts {
+		timeouts[strings.ToLower(name)] = d
+	}
+	return &callSettings{
+		timeout:        cfg.Timeout,
+		methodTimeouts: timeouts,
+		hedging:        cfg.Hedging.Enabled,
+		hedgingDelay:   cfg.Hedging.Delay,
+	}
+}
+
+// deadlineInterceptor ограничивает unary вызов таймаутом метода
+func deadlineInterceptor(settings *atomic.Pointer[callSettings]) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		s := settings.Load()
+		d, ok := s.methodTimeouts[strings.ToLower(path.Base(method))]
+		if !ok {
+			d = s.timeout
+		}
+		if d > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, d)
+			defer cancel()
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// retryInterceptor повторяет чтения, завершившиеся Unavailable, с экспоненциальной
+// задержкой и полным джиттером, чтобы клиенты не приходили к поднявшемуся сервису разом
+func retryInterceptor(cfg Resilience) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if !readMethods[method] || cfg.Retry.MaxAttempts <= 1 {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		backoff := cfg.Retry.InitialBackoff
+		for attempt := 1; ; attempt++ {
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			if status.Code(err) != codes.Unavailable || attempt >= cfg.Retry.MaxAttempts {
+				return err
+			}
+
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(rand.N(backoff + 1)):
+			}
+			backoff = min(backoff*2, cfg.Retry.MaxBackoff)
+			clientRetries.WithLabelValues(method).Inc()
+		}
+	}
+}
+
+// hedgingInterceptor запускает второй такой же запрос на чтение, если первый
+// не ответил за delay, и возвращает первый успешный ответ
+func hedgingInterceptor(settings *atomic.Pointer[callSettings]) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		s := settings.Load()
+		msg, ok := reply.(proto.Message)
+		if !s.hedging || !readMethods[method] || !ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		delay := s.hedgingDelay
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		type result struct {
+			reply proto.Message
+			err   error
+		}
+		results := make(chan result, 2)
+		call := func() {
+			// Ответ пишется в свою копию, чтобы попытки не мешали друг другу
+			r := msg.ProtoReflect().New().Interface()
+			results <- result{reply: r, err: invoker(ctx, method, req, r, cc, opts...)}
+		}
+
+		go call()
+		pending := 1
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		var err error
+		for pending > 0 {
+			select {
+			case <-timer.C:
+				clientHedges.WithLabelValues(method).Inc()
+				pending++
+				go call()
+			case res := <-results:
+				pending--
+				if res.err == nil {
+					proto.Reset(msg)
+					proto.Merge(msg, res.reply)
+					return nil
+				}
+				err = res.err
+				if pending == 0 && timer.Stop() {
+					return err
+				}
+			}
+		}
+		return err
+	}
+}
+
+// breaker - автомат отключения вызовов к db сервису. После threshold отказов
+// подряд вызовы сразу завершаются Unavailable; через openTimeout пропускается
+// один пробный вызов, и его результат замыкает или снова размыкает цепь.
+type breaker struct {
+	threshold   int
+	openTimeout time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	open     bool
+	probing  bool
+}
+
+var errCircuitOpen = status.Error(codes.Unavailable, "circuit breaker is open")
+
+// isFailure - ошибки, говорящие о недоступности сервиса, а не о плохом запросе.
+// ResourceExhausted не отказ: перегруженный сервис отвечает и сбрасывает часть
+// вызовов, а разомкнутая цепь отклонила бы и те, что он успел бы обработать.
+func isFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Internal, codes.Unknown:
+		return true
+	}
+	return false
+}
+
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.open {
+		return true
+	}
+	if b.probing || time.Since(b.openedAt) < b.openTimeout {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+func (b *breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if !isFailure(err) {
+		b.failures = 0
+		if b.open {
+			b.open = false
+			clientCircuitOpen.Set(0)
+		}
+		return
+	}
+
+	b.failures++
+	if b.open || b.failures >= b.threshold {
+		b.open = true
+		b.openedAt = time.Now()
+		clientCircuitOpen.Set(1)
+	}
+}
+
+func (b *breaker) unaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if !b.allow() {
+			return errCircuitOpen
+		}
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		// Отмена запроса клиентом ничего не говорит о сервисе
+		if ctx.Err() != nil && status.Code(err) == codes.Canceled {
+			b.mu.Lock()
+			b.probing = false
+			b.mu.Unlock()
+			return err
+		}
+		b.record(err)
+		return err
+	}
+}
+
+func (b *breaker) streamInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if !b.allow() {
+			return nil, errCircuitOpen
+		}
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		// Учитывается только установка потока
+		b.record(err)
+		return stream, err
+	}
+}
@@ -0,0 +1,154 @@
+package client
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SteepTaq/todo_project/internal/api/domain"
+	pb "github.com/SteepTaq/todo_project/pkg/proto/gen/todo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type flakyServer struct {
+	pb.UnimplementedTodoServiceServer
+	calls     atomic.Int32
+	failFirst int32
+	slowFirst time.Duration
+}
+
+func (s *flakyServer) GetTask(ctx context.Context, req *pb.GetTaskRequest) (*pb.GetTaskResponse, error) {
+	n := s.calls.Add(1)
+	if n <= s.failFirst {
+		return nil, status.Error(codes.Unavailable, "restarting")
+	}
+	if n == 1 && s.slowFirst > 0 {
+		select {
+		case <-time.After(s.slowFirst):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return &pb.GetTaskResponse{Task: &pb.Task{TaskId: req.GetId()}}, nil
+}
+
+func (s *flakyServer) CreateTask(ctx context.Context, req *pb.CreateTaskRequest) (*pb.CreateTaskResponse, error) {
+	s.calls.Add(1)
+	return nil, status.Error(codes.Unavailable, "restarting")
+}
+
+func startClient(t *testing.T, srv *flakyServer, cfg Config) *DBClient {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := grpc.NewServer()
+	pb.RegisterTodoServiceServer(s, srv)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	cfg.Target = lis.Addr().String()
+	cfg.Timeout = 5 * time.Second
+	c, err := NewDBClient(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	t.Cleanup(c.Close)
+	return c
+}
+
+func TestRetryReads(t *testing.T) {
+	srv := &flakyServer{failFirst: 2}
+	cfg := Config{}
+	cfg.Retry.MaxAttempts = 3
+	cfg.Retry.InitialBackoff = time.Millisecond
+	cfg.Retry.MaxBackoff = 10 * time.Millisecond
+	c := startClient(t, srv, cfg)
+
+	task, err := c.GetTaskById(context.Background(), "1")
+	require.NoError(t, err)
+	assert.Equal(t, "1", task.ID)
+	assert.Equal(t, int32(3), srv.calls.Load())
+
+	// Запись не повторяется
+	srv.calls.Store(0)
+	_, err = c.CreateTask(context.Background(), domain.TaskFields{Title: "title"})
+	assert.ErrorIs(t, err, domain.ErrServiceUnavailable)
+	assert.Equal(t, int32(1), srv.calls.Load())
+}
+
+func TestBreakerFailsFast(t *testing.T) {
+	srv := &flakyServer{failFirst: 1 << 30}
+	cfg := Config{}
+	cfg.Breaker.FailureThreshold = 2
+	cfg.Breaker.OpenTimeout = 50 * time.Millisecond
+	c := startClient(t, srv, cfg)
+	ctx := context.Background()
+
+	for range 5 {
+		_, err := c.GetTaskById(ctx, "1")
+		assert.ErrorIs(t, err, domain.ErrServiceUnavailable)
+	}
+	assert.Equal(t, int32(2), srv.calls.Load())
+
+	// После open_timeout проходит пробный вызов, и успех замыкает цепь
+	srv.failFirst = 0
+	time.Sleep(60 * time.Millisecond)
+	_, err := c.GetTaskById(ctx, "1")
+	require.NoError(t, err)
+	_, err = c.GetTaskById(ctx, "1")
+	require.NoError(t, err)
+}
+
+func TestHedgedRead(t *testing.T) {
+	srv := &flakyServer{slowFirst: time.Second}
+	cfg := Config{}
+	cfg.Hedging.Enabled = true
+	cfg.Hedging.Delay = 20 * time.Millisecond
+	c := startClient(t, srv, cfg)
+
+	start := time.Now()
+	task, err := c.GetTaskById(context.Background(), "1")
+	require.NoError(t, err)
+	assert.Equal(t, "1", task.ID)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.Equal(t, int32(2), srv.calls.Load())
+}
+
+type sheddingServer struct {
+	pb.UnimplementedTodoServiceServer
+	calls atomic.Int32
+}
+
+func (s *sheddingServer) GetAllTasks(ctx context.Context, req *pb.GetAllTasksRequest) (*pb.GetAllTasksResponse, error) {
+	s.calls.Add(1)
+	return nil, status.Error(codes.ResourceExhausted, "server is overloaded, retry later")
+}
+
+func TestBreakerIgnoresLoadShedding(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := &sheddingServer{}
+	s := grpc.NewServer()
+	pb.RegisterTodoServiceServer(s, srv)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	cfg := Config{Target: lis.Addr().String(), Timeout: 5 * time.Second}
+	cfg.Breaker.FailureThreshold = 2
+	cfg.Breaker.OpenTimeout = time.Minute
+	c, err := NewDBClient(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	t.Cleanup(c.Close)
+
+	// Сброс нагрузки не размыкает цепь: каждый вызов доходит до сервиса
+	for range 5 {
+		_, err := c.GetAllTasks(context.Background())
+		assert.ErrorIs(t, err, domain.ErrServiceOverloaded)
+	}
+	assert.Equal(t, int32(5), srv.calls.Load())
+}
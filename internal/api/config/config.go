@@ -1,50 +1,278 @@
 package config
 
 import (
+	"log/slog"
 	"time"
 
-	"github.com/spf13/viper"
+	"github.com/SteepTaq/todo_project/internal/api/client"
+	"github.com/SteepTaq/todo_project/internal/api/ratelimit"
+	"github.com/SteepTaq/todo_project/pkg/conf"
+	"github.com/SteepTaq/todo_project/pkg/logger"
+	"github.com/SteepTaq/todo_project/pkg/middleware"
+	"github.com/SteepTaq/todo_project/pkg/tlsconfig"
+	"github.com/SteepTaq/todo_project/pkg/tracing"
 )
 
 type Config struct {
 	HTTP struct {
-		Port    string        `mapstructure:"port"`
-		Timeout time.Duration `mapstructure:"timeout"`
+		Port    string           `mapstructure:"port"`
+		Timeout time.Duration    `mapstructure:"timeout"`
+		TLS     tlsconfig.Config `mapstructure:"tls"`
+		// Адреса и подсети прокси, которым можно верить в X-Forwarded-For и X-Real-IP
+		TrustedProxies []string `mapstructure:"trusted_proxies"`
 	} `mapstructure:"http"`
 
-	GRPC struct {
-		Target  string        `mapstructure:"target"`
-		Timeout time.Duration `mapstructure:"timeout"`
-	} `mapstructure:"grpc_db_service"`
+	GRPC client.Config `mapstructure:"grpc_db_service"`
 
 	Kafka struct {
 		Brokers []string `mapstructure:"brokers"`
 		Topic   string   `mapstructure:"topic"`
 	} `mapstructure:"kafka"`
 
-	Logger struct {
-		Level string `mapstructure:"level"` 
-	} `mapstructure:"logger"`
+	Export struct {
+		PageSize int `mapstructure:"page_size"`
+	} `mapstructure:"export"`
+
+	Events struct {
+		Enabled          bool          `mapstructure:"enabled"`
+		HistorySize      int           `mapstructure:"history_size"`
+		SubscriberBuffer int           `mapstructure:"subscriber_buffer"`
+		Heartbeat        time.Duration `mapstructure:"heartbeat"`
+		Retry            time.Duration `mapstructure:"retry"`
+	} `mapstructure:"events"`
+
+	WebSocket struct {
+		AllowedOrigins []string      `mapstructure:"allowed_origins"`
+		SendBuffer     int           `mapstructure:"send_buffer"`
+		MaxMessageSize int64         `mapstructure:"max_message_size"`
+		PingPeriod     time.Duration `mapstructure:"ping_period"`
+		PongWait       time.Duration `mapstructure:"pong_wait"`
+		WriteWait      time.Duration `mapstructure:"write_wait"`
+	} `mapstructure:"websocket"`
+
+	Admin struct {
+		Token string `mapstructure:"token"`
+	} `mapstructure:"admin"`
+
+	Health struct {
+		CacheTTL time.Duration `mapstructure:"cache_ttl"`
+		Timeout  time.Duration `mapstructure:"timeout"`
+	} `mapstructure:"health"`
+
+	RateLimit ratelimit.Config `mapstructure:"rate_limit"`
+
+	Tracing tracing.Config `mapstructure:"tracing"`
+
+	AccessLog middleware.AccessLogConfig `mapstructure:"access_log"`
+
+	Logger logger.Config `mapstructure:"logger"`
+}
+
+// LoadConfig читает секцию api_service. Любое поле можно переопределить
+// переменной окружения TODO_API_SERVICE_<ключ>, например TODO_API_SERVICE_HTTP_PORT.
+func LoadConfig() (*Config, error) {
+	var cfg Config
+	if err := conf.Load("api_service", defaults(), &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// liveKeys применяются без перезапуска, остальные изменения требуют его
+var liveKeys = []string{
+	"logger.level",
+	"rate_limit.enabled",
+	"rate_limit.key_header",
+	"rate_limit.api_keys",
+	"rate_limit.groups",
+	"rate_limit.daily_create_quota",
+	"grpc_db_service.timeout",
+	"grpc_db_service.method_timeouts",
+	"grpc_db_service.hedging",
+}
+
+// Watch следит за файлом конфигурации и кладет в holder изменения liveKeys
+func Watch(holder *conf.Holder[Config], log *slog.Logger) error {
+	return conf.Watch("api_service", defaults(), liveKeys, holder, log)
+}
+
+func defaults() map[string]any {
+	return map[string]any{
+		"http": map[string]any{
+			"port":            "8081",
+			"timeout":         "10s",
+			"tls":             tlsconfig.Defaults(),
+			"trusted_proxies": []string{},
+		},
+		"grpc_db_service": map[string]any{
+			"target":          "localhost:50051",
+			"targets":         []string{},
+			"timeout":         "10s",
+			"method_timeouts": map[string]any{},
+			"retry": map[string]any{
+				"max_attempts":    3,
+				"initial_backoff": "100ms",
+				"max_backoff":     "1s",
+			},
+			"breaker": map[string]any{
+				"failure_threshold": 5,
+				"open_timeout":      "5s",
+			},
+			"hedging": map[string]any{
+				"enabled": false,
+				"delay":   "200ms",
+			},
+			"balancing": map[string]any{
+				"policy": "round_robin",
+				"keepalive": map[string]any{
+					"time":                  "30s",
+					"timeout":               "10s",
+					"permit_without_stream": true,
+				},
+				"outlier_detection": map[string]any{
+					"enabled":              false,
+					"interval":             "10s",
+					"base_ejection_time":   "30s",
+					"max_ejection_percent": 50,
+					"stdev_factor":         1900,
+					"minimum_hosts":        3,
+					"request_volume":       20,
+				},
+			},
+			"tls": tlsconfig.Defaults(),
+		},
+		"kafka": map[string]any{
+			"brokers": []string{"localhost:9094"},
+			"topic":   "events",
+		},
+		"export": map[string]any{
+			"page_size": 500,
+		},
+		"events": map[string]any{
+			"enabled":           true,
+			"history_size":      1024,
+			"subscriber_buffer": 64,
+			"heartbeat":         "15s",
+			"retry":             "3s",
+		},
+		"websocket": map[string]any{
+			"allowed_origins":  []string{},
+			"send_buffer":      64,
+			"max_message_size": 4096,
+			"ping_period":      "25s",
+			"pong_wait":        "60s",
+			"write_wait":       "10s",
+		},
+		"admin": map[string]any{
+			"token": "",
+		},
+		"health": map[string]any{
+			"cache_ttl": "2s",
+			"timeout":   "1s",
+		},
+		"rate_limit": map[string]any{
+			"enabled":            false,
+			"key_header":         "",
+			"api_keys":           []string{},
+			"groups":             map[string]any{},
+			"daily_create_quota": 0,
+			"redis": map[string]any{
+				"enabled":  false,
+				"addr":     "localhost:6379",
+				"password": "",
+				"db":       0,
+				"timeout":  "200ms",
+			},
+		},
+		"tracing": tracing.Defaults(),
+		"access_log": map[string]any{
+			"sample_rate": 1.0,
+		},
+		"logger": logger.Defaults(),
+	}
 }
 
-func LoadConfig() *Config {
+func (c *Config) Validate() error {
+	v := conf.NewValidator("api_service")
 
-	viper.SetConfigName("config")   
-	viper.SetConfigType("yaml")      
-	viper.AddConfigPath(".")         
-	viper.AddConfigPath("./configs")
+	v.Port("http.port", c.HTTP.Port)
+	v.Duration("http.timeout", c.HTTP.Timeout)
+	c.HTTP.TLS.Validate(v.Sub("http.tls"), true)
+	if _, err := middleware.ParseTrustedProxies(c.HTTP.TrustedProxies); err != nil {
+		v.Errorf("http.trusted_proxies", "%v", err)
+	}
 
-	if err := viper.ReadInConfig(); err != nil {
-		panic("failed to read config: " + err.Error())
+	grpc := v.Sub("grpc_db_service")
+	if c.GRPC.Target == "" && len(c.GRPC.Targets) == 0 {
+		grpc.Errorf("target", "target or targets is required")
 	}
-	subv := viper.Sub("api_service")
-	if subv == nil {
-		panic("missing 'api_service' section in config")
+	for _, target := range c.GRPC.Targets {
+		grpc.Address("targets", target)
 	}
-	var cfg Config
-	if err := subv.Unmarshal(&cfg); err != nil {
-		panic("failed to unmarshal config: " + err.Error())
+	grpc.Duration("timeout", c.GRPC.Timeout)
+	for method, d := range c.GRPC.MethodTimeouts {
+		grpc.Duration("method_timeouts."+method, d)
+	}
+	grpc.Positive("retry.max_attempts", c.GRPC.Retry.MaxAttempts)
+	if c.GRPC.Retry.MaxAttempts > 1 {
+		grpc.Duration("retry.initial_backoff", c.GRPC.Retry.InitialBackoff)
+		grpc.Duration("retry.max_backoff", c.GRPC.Retry.MaxBackoff)
 	}
+	if c.GRPC.Breaker.FailureThreshold > 0 {
+		grpc.Duration("breaker.open_timeout", c.GRPC.Breaker.OpenTimeout)
+	}
+	if c.GRPC.Hedging.Enabled {
+		grpc.Duration("hedging.delay", c.GRPC.Hedging.Delay)
+	}
+	grpc.OneOf("balancing.policy", c.GRPC.Balancing.Policy, "round_robin", "least_request", "pick_first")
+	c.GRPC.TLS.Validate(grpc.Sub("tls"), false)
+
+	v.Addresses("kafka.brokers", c.Kafka.Brokers)
+	v.Required("kafka.topic", c.Kafka.Topic)
+	v.Positive("export.page_size", c.Export.PageSize)
+
+	if c.Events.Enabled {
+		v.Positive("events.history_size", c.Events.HistorySize)
+		v.Positive("events.subscriber_buffer", c.Events.SubscriberBuffer)
+		v.Duration("events.heartbeat", c.Events.Heartbeat)
+		v.Duration("events.retry", c.Events.Retry)
+	}
+
+	v.Positive("websocket.send_buffer", c.WebSocket.SendBuffer)
+	if c.WebSocket.MaxMessageSize <= 0 {
+		v.Errorf("websocket.max_message_size", "must be positive, got %d", c.WebSocket.MaxMessageSize)
+	}
+	v.Duration("websocket.ping_period", c.WebSocket.PingPeriod)
+	v.Duration("websocket.write_wait", c.WebSocket.WriteWait)
+	if c.WebSocket.PongWait <= c.WebSocket.PingPeriod {
+		v.Errorf("websocket.pong_wait", "must be greater than ping_period")
+	}
+
+	v.Duration("health.timeout", c.Health.Timeout)
+
+	if c.RateLimit.Enabled {
+		for name, limit := range c.RateLimit.Groups {
+			group := v.Sub("rate_limit.groups." + name)
+			group.Positive("requests", limit.Requests)
+			group.Duration("per", limit.Per)
+		}
+		if c.RateLimit.KeyHeader != "" && len(c.RateLimit.APIKeys) == 0 {
+			v.Errorf("rate_limit.api_keys", "must not be empty when key_header is set")
+		}
+		for _, entry := range c.RateLimit.APIKeys {
+			if !ratelimit.ValidAPIKey(entry) {
+				v.Errorf("rate_limit.api_keys", "entries must look like <workspace>:<key>")
+				break
+			}
+		}
+		if c.RateLimit.Redis.Enabled {
+			v.Address("rate_limit.redis.addr", c.RateLimit.Redis.Addr)
+		}
+	}
+
+	c.Tracing.Validate(v.Sub("tracing"))
+	v.Fraction("access_log.sample_rate", c.AccessLog.SampleRate)
+	c.Logger.Validate(v.Sub("logger"))
 
-	return &cfg
+	return v.Err()
 }
@@ -10,4 +10,6 @@ var (
 	ErrInvalidInput       = errors.New("invalid input data")
 	ErrRequestTimeout     = errors.New("request timeout")
 	ErrServiceUnavailable = errors.New("service unavailable")
+	// ErrServiceOverloaded - db сервис сбрасывает нагрузку, запрос можно повторить позже
+	ErrServiceOverloaded = errors.New("service overloaded")
 )
@@ -5,10 +5,24 @@ import (
 )
 
 type Task struct {
-	ID          string    `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Status      string    `json:"status"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Status      string     `json:"status"`
+	Project     string     `json:"project"`
+	Assignee    string     `json:"assignee,omitempty"` // Исполнитель (email), пусто - не назначен
+	DueAt       *time.Time `json:"due_at,omitempty"`   // Срок выполнения, nil - без срока
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TaskFields - поля задачи, которые задает клиент при создании и изменении
+type TaskFields struct {
+	Title       string
+	Description string
+	// Номер статуса из todo.proto, учитывается только при изменении
+	Status   string
+	Project  string
+	Assignee string
+	DueAt    *time.Time
 }
@@ -0,0 +1,142 @@
+package events
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/SteepTaq/todo_project/internal/api/domain"
+)
+
+const (
+	TaskCreated = "task_created"
+	TaskUpdated = "task_updated"
+	TaskDeleted = "task_deleted"
+)
+
+type Event struct {
+	ID   uint64      `json:"id"`
+	Type string      `json:"event"`
+	Task domain.Task `json:"task"`
+	Time time.Time   `json:"time"`
+}
+
+// Filter отбирает события для подписчика. Пустые поля означают "без ограничений".
+// Меток у задач нет, фильтр по ним не поддерживается.
+type Filter struct {
+	Statuses []string
+	TaskIDs  []string
+	Projects []string
+}
+
+func (f Filter) Match(e Event) bool {
+	if len(f.Statuses) > 0 && e.Task.Status != "" && !slices.Contains(f.Statuses, e.Task.Status) {
+		return false
+	}
+	if len(f.Projects) > 0 && !slices.Contains(f.Projects, e.Task.Project) {
+		return false
+	}
+	if len(f.TaskIDs) > 0 && !slices.Contains(f.TaskIDs, e.Task.ID) {
+		return false
+	}
+	return true
+}
+
+// Broadcaster рассылает изменения задач подписчикам внутри процесса и хранит
+// последние события, чтобы переподключившийся клиент мог продолжить с Last-Event-ID.
+type Broadcaster struct {
+	mu      sync.Mutex
+	nextID  uint64
+	history []Event
+	size    int
+	subs    map[*Subscription]struct{}
+	bufSize int
+}
+
+type Subscription struct {
+	C      <-chan Event
+	ch     chan Event
+	filter Filter
+}
+
+func NewBroadcaster(historySize, subscriberBuffer int) *Broadcaster {
+	return &Broadcaster{
+		// ID начинаются с времени запуска, чтобы после рестарта сервиса
+		// новые события не терялись у клиентов со старым Last-Event-ID
+		nextID:  uint64(time.Now().UnixMilli()) * 1000,
+		history: make([]Event, 0, historySize),
+		size:    historySize,
+		subs:    make(map[*Subscription]struct{}),
+		bufSize: subscriberBuffer,
+	}
+}
+
+func (b *Broadcaster) Publish(eventType string, task domain.Task) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	e := Event{
+		ID:   b.nextID,
+		Type: eventType,
+		Task: task,
+		Time: time.Now(),
+	}
+
+	if len(b.history) == b.size {
+		copy(b.history, b.history[1:])
+		b.history = b.history[:b.size-1]
+	}
+	b.history = append(b.history, e)
+
+	for sub := range b.subs {
+		if !sub.filter.Match(e) {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			// Медленный подписчик: отключаем, клиент переподключится с Last-Event-ID
+			delete(b.subs, sub)
+			close(sub.ch)
+		}
+	}
+
+	return e
+}
+
+// Subscribe регистрирует подписчика и возвращает события после lastID, которые он пропустил.
+// complete == false, если часть пропущенных событий уже вытеснена из истории.
+func (b *Broadcaster) Subscribe(filter Filter, lastID uint64) (sub *Subscription, replay []Event, complete bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan Event, b.bufSize)
+	sub = &Subscription{C: ch, ch: ch, filter: filter}
+	b.subs[sub] = struct{}{}
+
+	complete = true
+	if lastID == 0 {
+		return sub, nil, complete
+	}
+	if len(b.history) > 0 && b.history[0].ID > lastID+1 {
+		complete = false
+	}
+	for _, e := range b.history {
+		if e.ID > lastID && filter.Match(e) {
+			replay = append(replay, e)
+		}
+	}
+
+	return sub, replay, complete
+}
+
+func (b *Broadcaster) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.ch)
+	}
+}
@@ -0,0 +1,71 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/SteepTaq/todo_project/internal/api/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribeReplaysMissedEvents(t *testing.T) {
+	b := NewBroadcaster(10, 4)
+
+	first := b.Publish(TaskCreated, domain.Task{ID: "1", Status: "TASK_STATUS_PENDING"})
+	b.Publish(TaskCreated, domain.Task{ID: "2", Status: "TASK_STATUS_COMPLETED"})
+	b.Publish(TaskUpdated, domain.Task{ID: "1", Status: "TASK_STATUS_IN_PROGRESS"})
+
+	sub, replay, complete := b.Subscribe(Filter{TaskIDs: []string{"1"}}, first.ID)
+	defer b.Unsubscribe(sub)
+
+	assert.True(t, complete)
+	require.Len(t, replay, 1)
+	assert.Equal(t, TaskUpdated, replay[0].Type)
+
+	b.Publish(TaskDeleted, domain.Task{ID: "2"})
+	b.Publish(TaskDeleted, domain.Task{ID: "1"})
+
+	e := <-sub.C
+	assert.Equal(t, TaskDeleted, e.Type)
+	assert.Equal(t, "1", e.Task.ID)
+}
+
+func TestSubscribeReportsTruncatedHistory(t *testing.T) {
+	b := NewBroadcaster(2, 4)
+
+	first := b.Publish(TaskCreated, domain.Task{ID: "1"})
+	b.Publish(TaskCreated, domain.Task{ID: "2"})
+	b.Publish(TaskCreated, domain.Task{ID: "3"})
+	b.Publish(TaskCreated, domain.Task{ID: "4"})
+
+	sub, replay, complete := b.Subscribe(Filter{}, first.ID)
+	defer b.Unsubscribe(sub)
+
+	assert.False(t, complete)
+	assert.Len(t, replay, 2)
+}
+
+func TestSlowSubscriberIsDisconnected(t *testing.T) {
+	b := NewBroadcaster(10, 1)
+
+	sub, _, _ := b.Subscribe(Filter{}, 0)
+	b.Publish(TaskCreated, domain.Task{ID: "1"})
+	b.Publish(TaskCreated, domain.Task{ID: "2"})
+
+	<-sub.C
+	_, ok := <-sub.C
+	assert.False(t, ok)
+
+	// Повторная отписка не должна паниковать
+	b.Unsubscribe(sub)
+}
+
+func TestFilterByProject(t *testing.T) {
+	f := Filter{Projects: []string{"web"}}
+
+	assert.True(t, f.Match(Event{Type: TaskCreated, Task: domain.Task{ID: "1", Project: "web"}}))
+	assert.False(t, f.Match(Event{Type: TaskUpdated, Task: domain.Task{ID: "2", Project: "mobile"}}))
+	assert.False(t, f.Match(Event{Type: TaskCreated, Task: domain.Task{ID: "3"}}))
+	assert.True(t, f.Match(Event{Type: TaskDeleted, Task: domain.Task{ID: "1", Project: "web"}}))
+	assert.False(t, f.Match(Event{Type: TaskDeleted, Task: domain.Task{ID: "2", Project: "mobile"}}))
+}
@@ -3,55 +3,93 @@ package handler
 import (
 	contex "context"
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
 	"strconv"
+	"sync"
+	"time"
 
+	"github.com/SteepTaq/todo_project/internal/api/board"
 	"github.com/SteepTaq/todo_project/internal/api/config"
 	"github.com/SteepTaq/todo_project/internal/api/domain"
+	"github.com/SteepTaq/todo_project/internal/api/events"
 	"github.com/SteepTaq/todo_project/internal/api/kafka"
 	"github.com/SteepTaq/todo_project/pkg/context"
 	"github.com/SteepTaq/todo_project/pkg/response"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
+	"github.com/gorilla/websocket"
 )
 
 type TodoHandler struct {
 	cfg      *config.Config
 	service  DBClientInterface
 	producer *kafka.Producer
+	events   *events.Broadcaster
+	boards   *board.Hub
+	upgrader *websocket.Upgrader
+
+	closing   chan struct{}
+	closeOnce sync.Once
 }
 type DBClientInterface interface {
-	CreateTask(ctx contex.Context, title, description string) (*domain.Task, error)
+	CreateTask(ctx contex.Context, fields domain.TaskFields) (*domain.Task, error)
 	GetAllTasks(ctx contex.Context) ([]domain.Task, error)
+	StreamTasks(ctx contex.Context, pageSize int, fn func([]domain.Task) error) error
 	GetTaskById(ctx contex.Context, id string) (*domain.Task, error)
-	UpdateTask(ctx contex.Context, id, title, description, status string) (*domain.Task, error)
-	DeleteTask(ctx contex.Context, id int32) error
+	UpdateTask(ctx contex.Context, id string, fields domain.TaskFields) (*domain.Task, error)
+	DeleteTask(ctx contex.Context, id string) error
 	Close()
 }
 
-func NewTodoHandler(cfg *config.Config, service DBClientInterface, producer *kafka.Producer) *TodoHandler {
-	return &TodoHandler{
+func NewTodoHandler(cfg *config.Config, service DBClientInterface, producer *kafka.Producer, broadcaster *events.Broadcaster) *TodoHandler {
+	h := &TodoHandler{
 		cfg:      cfg,
 		service:  service,
 		producer: producer,
+		events:   broadcaster,
+		boards:   board.NewHub(),
+		closing:  make(chan struct{}),
 	}
+	h.upgrader = h.newUpgrader()
+	return h
+}
+
+// CloseStreams завершает открытые SSE потоки. Вызывается при остановке сервера,
+// иначе Shutdown ждет их до своего таймаута.
+func (h *TodoHandler) CloseStreams() {
+	h.closeOnce.Do(func() { close(h.closing) })
 }
 
-func (h *TodoHandler) RegisterRoutes(router chi.Router) {
+// RegisterRoutes регистрирует маршруты задач. createMiddlewares применяются
+// только к созданию задачи, например дневная квота.
+func (h *TodoHandler) RegisterRoutes(router chi.Router, createMiddlewares ...func(http.Handler) http.Handler) {
 	router.Get("/list", h.GetAllTasks)
 	router.Get("/list/{id}", h.GetTaskById)
-	router.Post("/create", h.CreateTask)
+	router.With(createMiddlewares...).Post("/create", h.CreateTask)
 	router.Put("/update/{id}", h.UpdateTask)
 	router.Delete("/delete/{id}", h.DeleteTask)
 }
 
+// StreamRequested - запрошена ли выгрузка /list?stream=ndjson. Она может идти
+// дольше таймаута маршрутов задач, поэтому таймаут к ней не применяется.
+func StreamRequested(r *http.Request) bool {
+	return r.URL.Path == "/list" && r.URL.Query().Get("stream") == "ndjson"
+}
+
 func (h *TodoHandler) GetAllTasks(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	logger := context.LoggerFromContext(ctx)
+	if StreamRequested(r) {
+		h.ExportTasks(w, r)
+		return
+	}
+
 	tasks, err := h.service.GetAllTasks(ctx)
 	if err != nil {
 		logger.Error("failed to get tasks", "error", err)
-		response.Json(w, map[string]string{"error": "task not found"}, http.StatusInternalServerError)
+		h.writeError(w, err, "task not found")
 		return
 	}
 
@@ -67,7 +105,7 @@ func (h *TodoHandler) GetTaskById(w http.ResponseWriter, r *http.Request) {
 	task, err := h.service.GetTaskById(ctx, id)
 	if err != nil {
 		logger.Error("Failed to get task", "task_id", id, "error", err)
-		response.Json(w, map[string]string{"error": "task not found"}, http.StatusNotFound)
+		h.writeError(w, err, "failed to get task")
 		return
 	}
 
@@ -77,8 +115,11 @@ func (h *TodoHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	logger := context.LoggerFromContext(ctx)
 	var requestData struct {
-		Title       string `json:"title"`
-		Description string `json:"description"`
+		Title       string     `json:"title"`
+		Description string     `json:"description"`
+		Project     string     `json:"project"`
+		Assignee    string     `json:"assignee"`
+		DueAt       *time.Time `json:"due_at"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
@@ -88,25 +129,23 @@ func (h *TodoHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Вызываем gRPC клиент
-	task, err := h.service.CreateTask(ctx, requestData.Title, requestData.Description)
+	task, err := h.service.CreateTask(ctx, domain.TaskFields{
+		Title:       requestData.Title,
+		Description: requestData.Description,
+		Project:     requestData.Project,
+		Assignee:    requestData.Assignee,
+		DueAt:       requestData.DueAt,
+	})
 	if err != nil {
 		logger.Error("Failed to create task", "error", err)
-		response.Json(w, map[string]string{"error": "failed to create task"}, http.StatusInternalServerError)
+		h.writeError(w, err, "failed to create task")
 		return
 	}
 
-	// Отправляем событие в Kafka
-	if h.producer != nil {
-		msg := struct {
-			Event string      `json:"event"`
-			Task  interface{} `json:"task"`
-		}{
-			Event: "task_created",
-			Task:  task,
-		}
-		if data, err := json.Marshal(msg); err == nil {
-			h.producer.SendEvent(ctx, string(data))
-		}
+	// Оповещаем подписчиков SSE и Kafka
+	h.notify(ctx, events.TaskCreated, task)
+	if task.Assignee != "" {
+		h.sendEvent(ctx, "task_assigned", task)
 	}
 
 	response.Json(w, task, http.StatusCreated)
@@ -119,9 +158,12 @@ func (h *TodoHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
 	var requestData struct {
-		Title       string `json:"title"`
-		Description string `json:"description"`
-		Status      string `json:"status"`
+		Title       string       `json:"title"`
+		Description string       `json:"description"`
+		Status      string       `json:"status"`
+		Project     *string      `json:"project"`
+		Assignee    *string      `json:"assignee"`
+		DueAt       optionalTime `json:"due_at"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
@@ -130,13 +172,41 @@ func (h *TodoHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	task, err := h.service.UpdateTask(ctx, id, requestData.Title, requestData.Description, requestData.Status)
+	// Текущая задача нужна для незаданных полей и чтобы оповещать только об изменениях
+	prev, err := h.service.GetTaskById(ctx, id)
 	if err != nil {
 		logger.Error("failed to update task", "id", id, "error", err)
-		response.Json(w, map[string]string{"error": "failed to update task"}, http.StatusInternalServerError)
+		h.writeError(w, err, "failed to update task")
+		return
+	}
 
+	fields := domain.TaskFields{
+		Title:       requestData.Title,
+		Description: requestData.Description,
+		Status:      requestData.Status,
+		Project:     prev.Project,
+		Assignee:    prev.Assignee,
+		DueAt:       prev.DueAt,
+	}
+	if requestData.Project != nil {
+		fields.Project = *requestData.Project
+	}
+	if requestData.Assignee != nil {
+		fields.Assignee = *requestData.Assignee
+	}
+	if requestData.DueAt.Set {
+		fields.DueAt = requestData.DueAt.Value
+	}
+
+	task, err := h.service.UpdateTask(ctx, id, fields)
+	if err != nil {
+		logger.Error("failed to update task", "id", id, "error", err)
+		h.writeError(w, err, "failed to update task")
 		return
 	}
+
+	h.notifyUpdated(ctx, prev, task)
+
 	response.Json(w, task, http.StatusOK)
 }
 
@@ -144,25 +214,103 @@ func (h *TodoHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	log := context.LoggerFromContext(ctx)
 
-	idStr := chi.URLParam(r, "id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		log.Error("invalid task ID", "id", idStr, "error", err)
-		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, map[string]string{"error": "invalid task ID"})
-		return
-	}
+	id := chi.URLParam(r, "id")
 
-	err = h.service.DeleteTask(ctx, int32(id))
+	// Задача читается до удаления, чтобы подписчики получили ее проект и статус
+	task, err := h.service.GetTaskById(ctx, id)
+	if err == nil {
+		err = h.service.DeleteTask(ctx, id)
+	}
 	if err != nil {
 		log.Error("failed to delete task", "id", id, "error", err)
-		render.Status(r, http.StatusInternalServerError)
-		render.JSON(w, r, map[string]string{"error": "failed to delete task"})
+		h.writeError(w, err, "failed to delete task")
 		return
 	}
 
+	h.notify(ctx, events.TaskDeleted, task)
+
 	render.Status(r, http.StatusOK)
 	render.JSON(w, r, map[string]string{
 		"message": "task deleted successfully",
 	})
 }
+
+// writeError отвечает кодом, соответствующим ошибке db сервиса, и message для прочих ошибок.
+// Пока db сервис недоступен, клиент получает 503 с Retry-After.
+func (h *TodoHandler) writeError(w http.ResponseWriter, err error, message string) {
+	text, code := errorStatus(err, message)
+	switch code {
+	case http.StatusServiceUnavailable:
+		retryAfter := max(int(math.Ceil(h.cfg.GRPC.Breaker.OpenTimeout.Seconds())), 1)
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	case http.StatusTooManyRequests:
+		w.Header().Set("Retry-After", "1")
+	}
+	response.Json(w, map[string]string{"error": text}, code)
+}
+
+// errorStatus - текст ошибки для клиента и HTTP статус. Внутренние ошибки
+// заменяются на message, чтобы не отдавать наружу текст ошибок gRPC.
+func errorStatus(err error, message string) (string, int) {
+	switch {
+	case errors.Is(err, domain.ErrServiceUnavailable):
+		return "service unavailable", http.StatusServiceUnavailable
+	case errors.Is(err, domain.ErrServiceOverloaded):
+		return "service overloaded", http.StatusTooManyRequests
+	case errors.Is(err, domain.ErrRequestTimeout):
+		return "request timeout", http.StatusGatewayTimeout
+	case errors.Is(err, domain.ErrTaskNotFound):
+		return "task not found", http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidInput):
+		return "invalid input", http.StatusBadRequest
+	default:
+		return message, http.StatusInternalServerError
+	}
+}
+
+// notify рассылает изменение задачи подписчикам SSE и отправляет его в Kafka
+func (h *TodoHandler) notify(ctx contex.Context, event string, task *domain.Task) {
+	if h.events != nil {
+		h.events.Publish(event, *task)
+	}
+	h.sendEvent(ctx, event, task)
+}
+
+// notifyUpdated оповещает об изменении задачи, а в Kafka дополнительно
+// отправляет завершение и назначение, только если они произошли этим изменением
+func (h *TodoHandler) notifyUpdated(ctx contex.Context, prev, task *domain.Task) {
+	h.notify(ctx, events.TaskUpdated, task)
+	if task.Status == "TASK_STATUS_COMPLETED" && prev.Status != task.Status {
+		h.sendEvent(ctx, "task_completed", task)
+	}
+	if task.Assignee != "" && task.Assignee != prev.Assignee {
+		h.sendEvent(ctx, "task_assigned", task)
+	}
+}
+
+// optionalTime отличает отсутствующее поле от явного null, которым срок снимается
+type optionalTime struct {
+	Set   bool
+	Value *time.Time
+}
+
+func (o *optionalTime) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	return json.Unmarshal(data, &o.Value)
+}
+
+func (h *TodoHandler) sendEvent(ctx contex.Context, event string, task *domain.Task) {
+	if h.producer == nil {
+		return
+	}
+	msg := struct {
+		Event string      `json:"event"`
+		Task  interface{} `json:"task"`
+	}{
+		Event: event,
+		Task:  task,
+	}
+	if data, err := json.Marshal(msg); err == nil {
+		h.producer.SendEvent(ctx, string(data))
+	}
+}
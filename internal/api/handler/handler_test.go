@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/SteepTaq/todo_project/internal/api/config"
 	"github.com/SteepTaq/todo_project/internal/api/domain"
@@ -18,22 +19,26 @@ import (
 
 
 type createTaskService interface {
-	CreateTask(ctx contex.Context, title, description string) (*domain.Task, error)
+	CreateTask(ctx contex.Context, fields domain.TaskFields) (*domain.Task, error)
 	GetAllTasks(ctx contex.Context) ([]domain.Task, error)
+	StreamTasks(ctx contex.Context, pageSize int, fn func([]domain.Task) error) error
 	GetTaskById(ctx contex.Context, id string) (*domain.Task, error)
-	UpdateTask(ctx contex.Context, id, title, description, status string) (*domain.Task, error)
-	DeleteTask(ctx contex.Context, id int32) error
+	UpdateTask(ctx contex.Context, id string, fields domain.TaskFields) (*domain.Task, error)
+	DeleteTask(ctx contex.Context, id string) error
 	Close()
 }
 
 type mockService struct{}
 
-func (m *mockService) CreateTask(ctx contex.Context, title, description string) (*domain.Task, error) {
+func (m *mockService) CreateTask(ctx contex.Context, fields domain.TaskFields) (*domain.Task, error) {
 	return &domain.Task{
 		ID:          "1",
-		Title:       title,
-		Description: description,
+		Title:       fields.Title,
+		Description: fields.Description,
 		Status:      "pending",
+		Project:     fields.Project,
+		Assignee:    fields.Assignee,
+		DueAt:       fields.DueAt,
 	}, nil
 }
 
@@ -41,15 +46,19 @@ func (m *mockService) GetAllTasks(ctx contex.Context) ([]domain.Task, error) {
 	return nil, nil
 }
 
+func (m *mockService) StreamTasks(ctx contex.Context, pageSize int, fn func([]domain.Task) error) error {
+	return nil
+}
+
 func (m *mockService) GetTaskById(ctx contex.Context, id string) (*domain.Task, error) {
-	return nil, nil
+	return &domain.Task{ID: id, Status: "pending", Project: "main"}, nil
 }
 
-func (m *mockService) UpdateTask(ctx contex.Context, id, title, description, status string) (*domain.Task, error) {
+func (m *mockService) UpdateTask(ctx contex.Context, id string, fields domain.TaskFields) (*domain.Task, error) {
 	return nil, nil
 }
 
-func (m *mockService) DeleteTask(ctx contex.Context, id int32) error {
+func (m *mockService) DeleteTask(ctx contex.Context, id string) error {
 	return nil
 }
 
@@ -92,3 +101,41 @@ func TestCreateTask(t *testing.T) {
 	assert.Equal(t, "Test Task", resp.Title)
 	assert.Equal(t, "Test Description", resp.Description)
 }
+
+type unavailableService struct {
+	mockService
+}
+
+func (m *unavailableService) GetAllTasks(ctx contex.Context) ([]domain.Task, error) {
+	return nil, domain.ErrServiceUnavailable
+}
+
+func TestServiceUnavailable(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.GRPC.Breaker.OpenTimeout = 5 * time.Second
+	h := newTestTodoHandler(cfg, &unavailableService{}, nil)
+
+	w := httptest.NewRecorder()
+	h.GetAllTasks(w, httptest.NewRequest(http.MethodGet, "/list", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "5", w.Header().Get("Retry-After"))
+}
+
+type overloadedService struct {
+	mockService
+}
+
+func (m *overloadedService) GetAllTasks(ctx contex.Context) ([]domain.Task, error) {
+	return nil, domain.ErrServiceOverloaded
+}
+
+func TestServiceOverloaded(t *testing.T) {
+	h := newTestTodoHandler(&config.Config{}, &overloadedService{}, nil)
+
+	w := httptest.NewRecorder()
+	h.GetAllTasks(w, httptest.NewRequest(http.MethodGet, "/list", nil))
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+}
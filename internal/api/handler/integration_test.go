@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"bytes"
+	contex "context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SteepTaq/todo_project/internal/api/client"
+	"github.com/SteepTaq/todo_project/internal/api/config"
+	"github.com/SteepTaq/todo_project/internal/api/domain"
+	"github.com/SteepTaq/todo_project/internal/dbservice/repository"
+	"github.com/SteepTaq/todo_project/internal/dbservice/server"
+	"github.com/SteepTaq/todo_project/internal/dbservice/service"
+	todov1 "github.com/SteepTaq/todo_project/pkg/proto/gen/todo"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// newTestStack поднимает в процессе db сервис на хранилище и кеше в памяти,
+// gRPC клиент API и HTTP обработчики. Возвращает адрес HTTP сервера.
+func newTestStack(t *testing.T) string {
+	t.Helper()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	repo := repository.NewMemoryRepo(log)
+	cache := repository.NewMemoryCache(repository.CachePolicy{TTL: time.Minute, NegativeTTL: time.Minute})
+	grpcServer := grpc.NewServer()
+	todov1.RegisterTodoServiceServer(grpcServer, server.NewGRPCServer(service.NewTaskService(repo, cache, log)))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	clientCfg := client.Config{Target: listener.Addr().String(), Timeout: 5 * time.Second}
+	clientCfg.Breaker.FailureThreshold = 5
+	clientCfg.Breaker.OpenTimeout = time.Minute
+	dbClient, err := client.NewDBClient(clientCfg, log)
+	require.NoError(t, err)
+	t.Cleanup(dbClient.Close)
+
+	r := chi.NewRouter()
+	cfg := &config.Config{}
+	cfg.Export.PageSize = 2
+	h := NewTodoHandler(cfg, dbClient, nil, nil)
+	h.RegisterRoutes(r)
+	h.RegisterStreamRoutes(r)
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func doJSON(t *testing.T, method, url string, body any, out any) int {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(data)
+	}
+	ctx, cancel := contex.WithTimeout(contex.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	if out != nil && resp.StatusCode < 300 {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+	}
+	return resp.StatusCode
+}
+
+func TestIntegrationTaskLifecycle(t *testing.T) {
+	url := newTestStack(t)
+
+	var created domain.Task
+	status := doJSON(t, http.MethodPost, url+"/create", map[string]string{"title": "Write tests", "description": "in-process", "project": "qa"}, &created)
+	require.Equal(t, http.StatusCreated, status)
+	require.NotEmpty(t, created.ID)
+	assert.Equal(t, "TASK_STATUS_PENDING", created.Status)
+	assert.Equal(t, "qa", created.Project)
+
+	var got domain.Task
+	require.Equal(t, http.StatusOK, doJSON(t, http.MethodGet, url+"/list/"+created.ID, nil, &got))
+	assert.Equal(t, "Write tests", got.Title)
+	assert.Equal(t, "in-process", got.Description)
+	assert.Equal(t, "qa", got.Project)
+
+	// Статус передается номером из todo.proto: 2 - TASK_STATUS_COMPLETED
+	var updated domain.Task
+	status = doJSON(t, http.MethodPut, url+"/update/"+created.ID, map[string]string{"title": "Write more tests", "status": "2"}, &updated)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "Write more tests", updated.Title)
+	assert.Equal(t, "TASK_STATUS_COMPLETED", updated.Status)
+	assert.Equal(t, "qa", updated.Project, "project is kept when absent from the update")
+	assert.True(t, updated.CreatedAt.Equal(got.CreatedAt))
+
+	var moved domain.Task
+	require.Equal(t, http.StatusOK, doJSON(t, http.MethodPut, url+"/update/"+created.ID, map[string]string{"title": "Write more tests", "status": "2", "project": ""}, &moved))
+	assert.Empty(t, moved.Project)
+
+	var tasks []domain.Task
+	require.Equal(t, http.StatusOK, doJSON(t, http.MethodGet, url+"/list", nil, &tasks))
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "Write more tests", tasks[0].Title)
+
+	// NDJSON выгрузка отдается самим /list, без редиректа
+	resp, err := http.Get(url + "/list?stream=ndjson")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Nil(t, resp.Request.Response, "unexpected redirect")
+	assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+	var exported domain.Task
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&exported))
+	assert.Equal(t, created.ID, exported.ID)
+
+	require.Equal(t, http.StatusOK, doJSON(t, http.MethodDelete, url+"/delete/"+created.ID, nil, nil))
+	assert.Equal(t, http.StatusNotFound, doJSON(t, http.MethodGet, url+"/list/"+created.ID, nil, nil))
+	assert.Equal(t, http.StatusNotFound, doJSON(t, http.MethodDelete, url+"/delete/"+created.ID, nil, nil))
+}
+
+func TestIntegrationBadRequestsKeepBreakerClosed(t *testing.T) {
+	url := newTestStack(t)
+
+	var created domain.Task
+	require.Equal(t, http.StatusCreated, doJSON(t, http.MethodPost, url+"/create", map[string]string{"title": "existing"}, &created))
+
+	// Ошибки запроса - не отказ db сервиса, цепь после них не размыкается
+	for range 10 {
+		assert.Equal(t, http.StatusNotFound, doJSON(t, http.MethodPut, url+"/update/missing", map[string]string{"title": "x", "status": "0"}, nil))
+		assert.Equal(t, http.StatusBadRequest, doJSON(t, http.MethodPut, url+"/update/"+created.ID, map[string]string{"title": "", "status": "0"}, nil))
+		assert.Equal(t, http.StatusBadRequest, doJSON(t, http.MethodPost, url+"/create", map[string]string{"title": strings.Repeat("x", 256)}, nil))
+	}
+	assert.Equal(t, http.StatusCreated, doJSON(t, http.MethodPost, url+"/create", map[string]string{"title": "still works"}, nil))
+}
+
+func TestIntegrationAssigneeAndDueAt(t *testing.T) {
+	url := newTestStack(t)
+
+	due := time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)
+	var created domain.Task
+	status := doJSON(t, http.MethodPost, url+"/create", map[string]any{"title": "Review", "assignee": "bob@example.com", "due_at": due}, &created)
+	require.Equal(t, http.StatusCreated, status)
+	assert.Equal(t, "bob@example.com", created.Assignee)
+	require.NotNil(t, created.DueAt)
+	assert.True(t, created.DueAt.Equal(due))
+
+	// Без assignee и due_at в запросе исполнитель и срок не меняются
+	var updated domain.Task
+	require.Equal(t, http.StatusOK, doJSON(t, http.MethodPut, url+"/update/"+created.ID, map[string]string{"title": "Review again", "status": "1"}, &updated))
+	assert.Equal(t, "bob@example.com", updated.Assignee)
+	require.NotNil(t, updated.DueAt)
+	assert.True(t, updated.DueAt.Equal(due))
+
+	// null снимает срок
+	var cleared domain.Task
+	require.Equal(t, http.StatusOK, doJSON(t, http.MethodPut, url+"/update/"+created.ID, map[string]any{"title": "Review again", "status": "1", "assignee": "", "due_at": nil}, &cleared))
+	assert.Empty(t, cleared.Assignee)
+	assert.Nil(t, cleared.DueAt)
+}
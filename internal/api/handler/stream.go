@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SteepTaq/todo_project/internal/api/domain"
+	"github.com/SteepTaq/todo_project/internal/api/events"
+	"github.com/SteepTaq/todo_project/pkg/context"
+	"github.com/SteepTaq/todo_project/pkg/response"
+	"github.com/go-chi/chi/v5"
+)
+
+// RegisterStreamRoutes регистрирует долгоживущие маршруты, которые нельзя
+// оборачивать в middleware.Timeout
+func (h *TodoHandler) RegisterStreamRoutes(router chi.Router) {
+	router.Get("/events/stream", h.StreamEvents)
+	router.Get("/ws/board", h.BoardSocket)
+	// Прежний адрес выгрузки, основной - /list?stream=ndjson
+	router.Get("/export", h.ExportTasks)
+}
+
+// StreamEvents отдает изменения задач как Server-Sent Events.
+// Фильтры: ?project=<name>, ?status=pending&status=completed и ?task_id=<id>,
+// значения можно перечислять через запятую.
+func (h *TodoHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := context.LoggerFromContext(ctx)
+
+	if h.events == nil {
+		response.Json(w, map[string]string{"error": "event stream is disabled"}, http.StatusNotFound)
+		return
+	}
+
+	var lastID uint64
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			response.Json(w, map[string]string{"error": "invalid Last-Event-ID"}, http.StatusBadRequest)
+			return
+		}
+		lastID = id
+	}
+
+	filter := events.Filter{
+		Statuses: normalizeStatuses(queryList(r, "status")),
+		TaskIDs:  queryList(r, "task_id"),
+		Projects: queryList(r, "project"),
+	}
+
+	rc := http.NewResponseController(w)
+	// Поток живет дольше WriteTimeout сервера
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		logger.Warn("failed to reset write deadline", "error", err)
+	}
+
+	sub, replay, complete := h.events.Subscribe(filter, lastID)
+	defer h.events.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", h.cfg.Events.Retry.Milliseconds())
+	if !complete {
+		// Часть событий потеряна - клиенту нужно перечитать /list
+		fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+	}
+	for _, e := range replay {
+		if err := writeEvent(w, e); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		logger.Error("streaming is not supported", "error", err)
+		return
+	}
+
+	logger.Debug("event stream opened", "last_event_id", lastID, "replayed", len(replay))
+
+	heartbeat := time.NewTicker(h.cfg.Events.Heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Debug("event stream closed")
+			return
+		case <-h.closing:
+			logger.Debug("event stream closed on shutdown")
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case e, ok := <-sub.C:
+			if !ok {
+				logger.Warn("event stream subscriber is too slow, disconnecting")
+				return
+			}
+			if err := writeEvent(w, e); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// ExportTasks отдает все задачи в формате NDJSON, сбрасывая буфер после каждой порции.
+// Если выгрузка прервалась после начала ответа, последней строкой пишется {"error": "..."}.
+func (h *TodoHandler) ExportTasks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := context.LoggerFromContext(ctx)
+
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		logger.Warn("failed to reset write deadline", "error", err)
+	}
+
+	started := false
+	enc := json.NewEncoder(w)
+	count := 0
+	err := h.service.StreamTasks(ctx, h.cfg.Export.PageSize, func(tasks []domain.Task) error {
+		if !started {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+		for _, task := range tasks {
+			if err := enc.Encode(task); err != nil {
+				return err
+			}
+		}
+		count += len(tasks)
+		return rc.Flush()
+	})
+	if err != nil {
+		logger.Error("failed to stream tasks", "error", err, "sent", count)
+		if !started {
+			h.writeError(w, err, "failed to get tasks")
+			return
+		}
+		enc.Encode(map[string]string{"error": "stream interrupted"})
+		return
+	}
+	if !started {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+	}
+
+	logger.Debug("tasks streamed", "count", count)
+}
+
+func writeEvent(w http.ResponseWriter, e events.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
+
+func queryList(r *http.Request, name string) []string {
+	var values []string
+	for _, v := range r.URL.Query()[name] {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				values = append(values, part)
+			}
+		}
+	}
+	return values
+}
+
+// normalizeStatuses приводит "completed" к виду "TASK_STATUS_COMPLETED", в котором статус приходит из db сервиса
+func normalizeStatuses(statuses []string) []string {
+	for i, s := range statuses {
+		s = strings.ToUpper(s)
+		if !strings.HasPrefix(s, "TASK_STATUS_") {
+			s = "TASK_STATUS_" + s
+		}
+		statuses[i] = s
+	}
+	return statuses
+}
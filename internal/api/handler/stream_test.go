@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"bufio"
+	contex "context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SteepTaq/todo_project/internal/api/config"
+	"github.com/SteepTaq/todo_project/internal/api/domain"
+	"github.com/SteepTaq/todo_project/internal/api/events"
+	pkgmiddleware "github.com/SteepTaq/todo_project/pkg/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamEventsFiltersByProjectAndClosesOnShutdown(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Events.Retry = time.Second
+	cfg.Events.Heartbeat = time.Minute
+
+	broadcaster := events.NewBroadcaster(16, 16)
+	h := NewTodoHandler(cfg, &mockService{}, nil, broadcaster)
+	r := chi.NewRouter()
+	h.RegisterStreamRoutes(r)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/events/stream?project=web")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	broadcaster.Publish(events.TaskCreated, domain.Task{ID: "1", Project: "mobile"})
+	broadcaster.Publish(events.TaskCreated, domain.Task{ID: "2", Project: "web"})
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	var data []string
+	timeout := time.After(2 * time.Second)
+	for len(data) == 0 {
+		select {
+		case line := <-lines:
+			if v, ok := strings.CutPrefix(line, "data: "); ok {
+				data = append(data, v)
+			}
+		case <-timeout:
+			t.Fatal("no event received")
+		}
+	}
+	assert.Contains(t, data[0], `"id":"2"`)
+
+	h.CloseStreams()
+	for {
+		select {
+		case _, ok := <-lines:
+			if !ok {
+				return
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("stream is not closed on shutdown")
+		}
+	}
+}
+
+// slowStreamService отдает задачи порциями с паузой между ними
+type slowStreamService struct {
+	mockService
+	pages int
+	pause time.Duration
+}
+
+func (s *slowStreamService) StreamTasks(ctx contex.Context, pageSize int, fn func([]domain.Task) error) error {
+	for i := range s.pages {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(s.pause):
+			}
+		}
+		if err := fn([]domain.Task{{ID: strconv.Itoa(i)}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestListNDJSONOutlivesRouteTimeout(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Export.PageSize = 1
+	h := NewTodoHandler(cfg, &slowStreamService{pages: 3, pause: 60 * time.Millisecond}, nil, nil)
+
+	r := chi.NewRouter()
+	r.Group(func(r chi.Router) {
+		r.Use(pkgmiddleware.Unless(StreamRequested, middleware.Timeout(50*time.Millisecond)))
+		h.RegisterRoutes(r)
+	})
+	srv := httptest.NewUnstartedServer(r)
+	srv.Config.WriteTimeout = 50 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/list?stream=ndjson")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+	var ids []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		ids = append(ids, scanner.Text())
+	}
+	require.NoError(t, scanner.Err())
+	require.Len(t, ids, 3)
+	assert.Contains(t, ids[2], `"id":"2"`)
+}
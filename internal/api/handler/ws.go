@@ -0,0 +1,256 @@
+package handler
+
+import (
+	contex "context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/SteepTaq/todo_project/internal/api/board"
+	"github.com/SteepTaq/todo_project/internal/api/domain"
+	"github.com/SteepTaq/todo_project/internal/api/events"
+	"github.com/SteepTaq/todo_project/pkg/context"
+	"github.com/SteepTaq/todo_project/pkg/response"
+	"github.com/gorilla/websocket"
+)
+
+// boardMessage - сообщение от клиента доски
+type boardMessage struct {
+	Type        string `json:"type"`
+	Ref         string `json:"ref,omitempty"`
+	TaskID      string `json:"task_id,omitempty"`
+	State       string `json:"state,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Status      string `json:"status,omitempty"`
+}
+
+// boardReply - ответ на правку от клиента
+type boardReply struct {
+	Type  string       `json:"type"`
+	Ref   string       `json:"ref,omitempty"`
+	Task  *domain.Task `json:"task,omitempty"`
+	Error string       `json:"error,omitempty"`
+}
+
+type boardDelta struct {
+	Type  string       `json:"type"`
+	Event events.Event `json:"event"`
+}
+
+func (h *TodoHandler) newUpgrader() *websocket.Upgrader {
+	u := &websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+	}
+	if origins := h.cfg.WebSocket.AllowedOrigins; len(origins) > 0 {
+		u.CheckOrigin = func(r *http.Request) bool {
+			return slices.Contains(origins, "*") || slices.Contains(origins, r.Header.Get("Origin"))
+		}
+	}
+	return u
+}
+
+// BoardSocket - WebSocket канал доски: ?board=<project>&user=<name>.
+// Доска показывает задачи одного проекта: клиент получает их изменения и
+// присутствие других пользователей доски и может сам создавать, менять и
+// удалять задачи. Без потока событий (events.enabled) доска не работает.
+func (h *TodoHandler) BoardSocket(w http.ResponseWriter, r *http.Request) {
+	logger := context.LoggerFromContext(r.Context())
+
+	if h.events == nil {
+		logger.Warn("board connection rejected: event stream is disabled")
+		response.Json(w, map[string]string{"error": "event stream is disabled"}, http.StatusNotFound)
+		return
+	}
+
+	boardName := r.URL.Query().Get("board")
+	user := r.URL.Query().Get("user")
+	if boardName == "" || user == "" {
+		response.Json(w, map[string]string{"error": "board and user are required"}, http.StatusBadRequest)
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger.Error("websocket upgrade failed", "error", err)
+		return
+	}
+	defer conn.Close()
+
+	logger = logger.With("board", boardName, "user", user)
+	ctx, cancel := contex.WithCancel(context.WithLogger(r.Context(), logger))
+	defer cancel()
+
+	client := h.boards.Join(boardName, user, h.cfg.WebSocket.SendBuffer)
+	defer h.boards.Leave(client)
+
+	sub, _, _ := h.events.Subscribe(events.Filter{Projects: []string{boardName}}, 0)
+	defer h.events.Unsubscribe(sub)
+
+	logger.Info("board connection opened")
+	go h.boardWriter(ctx, cancel, conn, client, sub.C)
+	h.boardReader(ctx, conn, client)
+	logger.Info("board connection closed")
+}
+
+func (h *TodoHandler) boardReader(ctx contex.Context, conn *websocket.Conn, client *board.Client) {
+	logger := context.LoggerFromContext(ctx)
+	cfg := h.cfg.WebSocket
+
+	conn.SetReadLimit(cfg.MaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(cfg.PongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(cfg.PongWait))
+	})
+
+	for {
+		var msg boardMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				logger.Warn("websocket read failed", "error", err)
+			}
+			return
+		}
+
+		reply := h.handleBoardMessage(ctx, client, msg)
+		if reply == nil {
+			continue
+		}
+		data, err := json.Marshal(reply)
+		if err != nil {
+			logger.Error("failed to marshal reply", "error", err)
+			continue
+		}
+		if !h.boards.Send(client, data) {
+			return
+		}
+	}
+}
+
+func (h *TodoHandler) handleBoardMessage(ctx contex.Context, client *board.Client, msg boardMessage) *boardReply {
+	logger := context.LoggerFromContext(ctx)
+
+	if msg.Type == "presence" {
+		h.boards.SetPresence(client, msg.TaskID, msg.State)
+		return nil
+	}
+
+	var (
+		task *domain.Task
+		err  error
+	)
+	switch msg.Type {
+	case "create":
+		task, err = h.service.CreateTask(ctx, domain.TaskFields{
+			Title:       msg.Title,
+			Description: msg.Description,
+			Project:     client.Board(),
+		})
+		if err == nil {
+			h.notify(ctx, events.TaskCreated, task)
+		}
+	case "update":
+		// Исполнителя и срок доска не меняет
+		var prev *domain.Task
+		if prev, err = h.boardTask(ctx, client, msg.TaskID); err == nil {
+			task, err = h.service.UpdateTask(ctx, msg.TaskID, domain.TaskFields{
+				Title:       msg.Title,
+				Description: msg.Description,
+				Status:      msg.Status,
+				Project:     client.Board(),
+				Assignee:    prev.Assignee,
+				DueAt:       prev.DueAt,
+			})
+		}
+		if err == nil {
+			h.notifyUpdated(ctx, prev, task)
+		}
+	case "delete":
+		if task, err = h.boardTask(ctx, client, msg.TaskID); err == nil {
+			err = h.service.DeleteTask(ctx, msg.TaskID)
+		}
+		if err == nil {
+			h.notify(ctx, events.TaskDeleted, task)
+		}
+	default:
+		return &boardReply{Type: "error", Ref: msg.Ref, Error: "unknown message type"}
+	}
+
+	if err != nil {
+		logger.Error("board edit failed", "type", msg.Type, "task_id", msg.TaskID, "error", err)
+		text, _ := errorStatus(err, "failed to "+msg.Type+" task")
+		return &boardReply{Type: "error", Ref: msg.Ref, Error: text}
+	}
+	return &boardReply{Type: "ack", Ref: msg.Ref, Task: task}
+}
+
+// boardTask загружает задачу и проверяет, что она на доске клиента.
+// Задачи других проектов для доски не существуют.
+func (h *TodoHandler) boardTask(ctx contex.Context, client *board.Client, id string) (*domain.Task, error) {
+	task, err := h.service.GetTaskById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if task.Project != client.Board() {
+		return nil, fmt.Errorf("task %s is in project %q: %w", id, task.Project, domain.ErrTaskNotFound)
+	}
+	return task, nil
+}
+
+func (h *TodoHandler) boardWriter(ctx contex.Context, cancel contex.CancelFunc, conn *websocket.Conn, client *board.Client, deltas <-chan events.Event) {
+	logger := context.LoggerFromContext(ctx)
+	cfg := h.cfg.WebSocket
+
+	ping := time.NewTicker(cfg.PingPeriod)
+	defer ping.Stop()
+	// Закрытие соединения прерывает ReadJSON в boardReader
+	defer conn.Close()
+	defer cancel()
+
+	write := func(messageType int, data []byte) bool {
+		conn.SetWriteDeadline(time.Now().Add(cfg.WriteWait))
+		if err := conn.WriteMessage(messageType, data); err != nil {
+			logger.Debug("websocket write failed", "error", err)
+			return false
+		}
+		return true
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+				time.Now().Add(cfg.WriteWait))
+			return
+		case <-ping.C:
+			if !write(websocket.PingMessage, nil) {
+				return
+			}
+		case msg, ok := <-client.C:
+			if !ok {
+				logger.Warn("board client is too slow, disconnecting")
+				return
+			}
+			if !write(websocket.TextMessage, msg) {
+				return
+			}
+		case e, ok := <-deltas:
+			if !ok {
+				logger.Warn("board client is too slow, disconnecting")
+				return
+			}
+			data, err := json.Marshal(boardDelta{Type: "delta", Event: e})
+			if err != nil {
+				logger.Error("failed to marshal delta", "error", err)
+				continue
+			}
+			if !write(websocket.TextMessage, data) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	contex "context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SteepTaq/todo_project/internal/api/config"
+	"github.com/SteepTaq/todo_project/internal/api/events"
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoardSocket(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.WebSocket.SendBuffer = 16
+	cfg.WebSocket.MaxMessageSize = 4096
+	cfg.WebSocket.PingPeriod = time.Second
+	cfg.WebSocket.PongWait = 5 * time.Second
+	cfg.WebSocket.WriteWait = time.Second
+
+	h := NewTodoHandler(cfg, &mockService{}, nil, events.NewBroadcaster(16, 16))
+	r := chi.NewRouter()
+	h.RegisterStreamRoutes(r)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	dial := func(board, user string) *websocket.Conn {
+		url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws/board?board=" + board + "&user=" + user
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		return conn
+	}
+	read := func(conn *websocket.Conn) map[string]any {
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		var msg map[string]any
+		require.NoError(t, conn.ReadJSON(&msg))
+		return msg
+	}
+
+	alice := dial("main", "alice")
+	assert.Equal(t, "presence", read(alice)["type"])
+
+	// Доска другого проекта не получает изменений main
+	carol := dial("other", "carol")
+	assert.Equal(t, "presence", read(carol)["type"])
+
+	bob := dial("main", "bob")
+	assert.Equal(t, "presence", read(bob)["type"])
+	presence := read(alice)
+	assert.Len(t, presence["users"], 2)
+
+	require.NoError(t, bob.WriteJSON(map[string]string{"type": "presence", "task_id": "1", "state": "editing"}))
+	presence = read(alice)
+	assert.Contains(t, presence["users"], map[string]any{"user": "bob", "task_id": "1", "state": "editing"})
+	read(bob)
+
+	require.NoError(t, alice.WriteJSON(map[string]string{"type": "create", "ref": "r1", "title": "From board"}))
+
+	delta := read(bob)
+	assert.Equal(t, "delta", delta["type"])
+	assert.Equal(t, events.TaskCreated, delta["event"].(map[string]any)["event"])
+	assert.Equal(t, "main", delta["event"].(map[string]any)["task"].(map[string]any)["project"])
+
+	// Автор правки получает и ack, и delta - порядок не гарантирован
+	got := map[string]bool{}
+	for range 2 {
+		got[read(alice)["type"].(string)] = true
+	}
+	assert.Equal(t, map[string]bool{"ack": true, "delta": true}, got)
+
+	// С доски other задачи проекта main не правятся и не удаляются
+	for _, typ := range []string{"update", "delete"} {
+		require.NoError(t, carol.WriteJSON(map[string]string{"type": typ, "ref": typ, "task_id": "1", "title": "Moved"}))
+		reply := read(carol)
+		assert.Equal(t, "error", reply["type"])
+		assert.Equal(t, "task not found", reply["error"])
+	}
+
+	// Удаление приходит только доскам проекта задачи
+	require.NoError(t, alice.WriteJSON(map[string]string{"type": "delete", "ref": "r2", "task_id": "1"}))
+	delta = read(bob)
+	assert.Equal(t, events.TaskDeleted, delta["event"].(map[string]any)["event"])
+	assert.Equal(t, "main", delta["event"].(map[string]any)["task"].(map[string]any)["project"])
+
+	carol.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	var msg map[string]any
+	assert.Error(t, carol.ReadJSON(&msg), "unexpected message %v", msg)
+}
+
+type failingService struct {
+	mockService
+}
+
+func (s *failingService) DeleteTask(ctx contex.Context, id string) error {
+	return errors.New("rpc error: code = Internal desc = connection refused")
+}
+
+func TestBoardSocketHidesInternalErrors(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.WebSocket.SendBuffer = 16
+	cfg.WebSocket.MaxMessageSize = 4096
+	cfg.WebSocket.PingPeriod = time.Second
+	cfg.WebSocket.PongWait = 5 * time.Second
+	cfg.WebSocket.WriteWait = time.Second
+
+	h := NewTodoHandler(cfg, &failingService{}, nil, events.NewBroadcaster(16, 16))
+	r := chi.NewRouter()
+	h.RegisterStreamRoutes(r)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws/board?board=main&user=alice"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, conn.WriteJSON(map[string]string{"type": "delete", "ref": "r1", "task_id": "1"}))
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		var msg map[string]any
+		require.NoError(t, conn.ReadJSON(&msg))
+		if msg["type"] == "error" {
+			assert.Equal(t, "failed to delete task", msg["error"])
+			return
+		}
+	}
+}
+
+func TestBoardSocketRequiresEvents(t *testing.T) {
+	h := NewTodoHandler(&config.Config{}, &mockService{}, nil, nil)
+	r := chi.NewRouter()
+	h.RegisterStreamRoutes(r)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ws/board?board=main&user=alice", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
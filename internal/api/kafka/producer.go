@@ -2,14 +2,30 @@ package kafka
 
 import (
 	"context"
+	"errors"
 	"log"
 
+	"github.com/SteepTaq/todo_project/pkg/tracing"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var producedMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "kafka_messages_produced_total",
+	Help: "Messages sent to Kafka by topic and result.",
+}, []string{"topic", "result"})
+
+var tracer = otel.Tracer("github.com/SteepTaq/todo_project/internal/api/kafka")
+
 type Producer struct {
-	writer *kafka.Writer
-	topic  string
+	writer  *kafka.Writer
+	topic   string
+	brokers []string
 }
 
 func NewProducer(brokers []string, topic string) *Producer {
@@ -20,26 +36,57 @@ func NewProducer(brokers []string, topic string) *Producer {
 			
 			Balancer: &kafka.LeastBytes{},
 		},
-		topic: topic,
+		topic:   topic,
+		brokers: brokers,
 	}
 }
 
 func (p *Producer) SendEvent(ctx context.Context, message string) error {
 	log.Printf("Attempting to send message to Kafka: %s", message)
 
+	ctx, span := tracer.Start(ctx, p.topic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingDestinationName(p.topic),
+		),
+	)
+	defer span.End()
+
 	msg := kafka.Message{
 		Value: []byte(message),
 	}
+	otel.GetTextMapPropagator().Inject(ctx, tracing.KafkaHeaders{Message: &msg})
 
 	err := p.writer.WriteMessages(ctx, msg)
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		producedMessages.WithLabelValues(p.topic, "error").Inc()
 		log.Printf("failed to send kafka message: %v", err)
 	} else {
+		producedMessages.WithLabelValues(p.topic, "success").Inc()
 		log.Printf("Message sent successfully to Kafka, topic: %s (using LeastBytes balancer)", p.topic)
 	}
 	return err
 }
 
+// Ping проверяет, что доступен хотя бы один брокер
+func (p *Producer) Ping(ctx context.Context) error {
+	var errs []error
+	for _, broker := range p.brokers {
+		conn, err := kafka.DialContext(ctx, "tcp", broker)
+		if err == nil {
+			return conn.Close()
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return errors.New("no kafka brokers configured")
+	}
+	return errors.Join(errs...)
+}
+
 func (p *Producer) Close() error {
 	if p.writer != nil {
 		return p.writer.Close()
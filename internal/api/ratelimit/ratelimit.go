@@ -0,0 +1,302 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	ctxLog "github.com/SteepTaq/todo_project/pkg/context"
+	"github.com/SteepTaq/todo_project/pkg/response"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+)
+
+var rateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "http_rate_limited_total",
+	Help: "HTTP requests rejected by rate limits and quotas by route group.",
+}, []string{"group"})
+
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Заголовок с API ключом. Учитываются только ключи из APIKeys, остальные
+	// клиенты определяются по IP.
+	KeyHeader string `mapstructure:"key_header"`
+	// Допустимые API ключи в виде "<рабочее пространство>:<ключ>"
+	APIKeys []string `mapstructure:"api_keys"`
+	// Лимиты по группам маршрутов
+	Groups map[string]Limit `mapstructure:"groups"`
+	// Сколько задач в сутки (UTC) может создать одно рабочее пространство, 0 - без квоты.
+	// Рабочее пространство определяется по API ключу, клиенты без ключа считаются по IP.
+	DailyCreateQuota int64 `mapstructure:"daily_create_quota"`
+
+	// С Redis лимиты общие для всех реплик API, без него - в памяти процесса
+	Redis struct {
+		Enabled  bool          `mapstructure:"enabled"`
+		Addr     string        `mapstructure:"addr"`
+		Password string        `mapstructure:"password"`
+		DB       int           `mapstructure:"db"`
+		Timeout  time.Duration `mapstructure:"timeout"`
+	} `mapstructure:"redis"`
+}
+
+// Limit - корзина токенов: Requests запросов за Per, не больше Burst подряд
+type Limit struct {
+	Requests int           `mapstructure:"requests"`
+	Per      time.Duration `mapstructure:"per"`
+	Burst    int           `mapstructure:"burst"`
+}
+
+func (l Limit) valid() bool {
+	return l.Requests > 0 && l.Per > 0
+}
+
+// rate - токенов в секунду
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+func (l Limit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Requests
+}
+
+// Decision - результат попытки взять токен
+type Decision struct {
+	Allowed   bool
+	Remaining int
+	// Через сколько корзина снова наполнится
+	Reset time.Duration
+	// Через сколько появится следующий токен, если запрос отклонен
+	RetryAfter time.Duration
+}
+
+// Store хранит состояние лимитов
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Decision, error)
+	// Incr меняет счетчик на delta и возвращает новое значение; счетчик живет ttl
+	Incr(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error)
+}
+
+type Limiter struct {
+	// Лимиты и квоты можно менять на лету через SetConfig, хранилище - нет
+	cfg   atomic.Pointer[Config]
+	store Store
+	log   *slog.Logger
+	close func()
+	now   func() time.Time
+}
+
+func New(cfg Config, log *slog.Logger) *Limiter {
+	l := &Limiter{
+		log:   log.With("component", "rate_limiter"),
+		close: func() {},
+		now:   time.Now,
+	}
+	l.cfg.Store(&cfg)
+	if cfg.Redis.Enabled {
+		client := redis.NewClient(&redis.Options{
+			Addr:         cfg.Redis.Addr,
+			Password:     cfg.Redis.Password,
+			DB:           cfg.Redis.DB,
+			DialTimeout:  cfg.Redis.Timeout,
+			ReadTimeout:  cfg.Redis.Timeout,
+			WriteTimeout: cfg.Redis.Timeout,
+		})
+		l.store = NewRedisStore(client)
+		l.close = func() { client.Close() }
+	} else {
+		l.store = NewMemoryStore()
+	}
+	return l
+}
+
+// NewWithStore - для тестов и своих хранилищ
+func NewWithStore(cfg Config, store Store, log *slog.Logger) *Limiter {
+	l := &Limiter{store: store, log: log.With("component", "rate_limiter"), close: func() {}, now: time.Now}
+	l.cfg.Store(&cfg)
+	return l
+}
+
+// SetConfig применяет новые лимиты и квоты. Настройки Redis не меняются.
+func (l *Limiter) SetConfig(cfg Config) {
+	l.cfg.Store(&cfg)
+}
+
+func (l *Limiter) Close() {
+	l.close()
+}
+
+// Middleware ограничивает частоту запросов группы маршрутов. Ответы получают
+// заголовки RateLimit-Limit, RateLimit-Remaining и RateLimit-Reset, отклоненные
+// запросы - 429 с Retry-After. Если хранилище недоступно, запросы пропускаются.
+func (l *Limiter) Middleware(group string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cfg := l.cfg.Load()
+			limit, ok := cfg.Groups[group]
+			if !cfg.Enabled || !ok || !limit.valid() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := fmt.Sprintf("ratelimit:%s:%s", group, clientKey(cfg, r))
+			d, err := l.store.Take(r.Context(), key, limit)
+			if err != nil {
+				ctxLog.LoggerFromContext(r.Context()).Warn("rate limiter unavailable", "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d;burst=%d", limit.Requests, seconds(limit.Per), limit.burst()))
+			h.Set("RateLimit-Limit", strconv.Itoa(limit.burst()))
+			h.Set("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+			h.Set("RateLimit-Reset", strconv.Itoa(seconds(d.Reset)))
+			if !d.Allowed {
+				rateLimited.WithLabelValues(group).Inc()
+				h.Set("Retry-After", strconv.Itoa(max(seconds(d.RetryAfter), 1)))
+				response.Json(w, map[string]string{"error": "rate limit exceeded"}, http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// DailyQuota ограничивает число созданных за сутки задач в рабочем пространстве.
+// Неуспешные запросы квоту не расходуют.
+func (l *Limiter) DailyQuota() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cfg := l.cfg.Load()
+			quota := cfg.DailyCreateQuota
+			if !cfg.Enabled || quota <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			logger := ctxLog.LoggerFromContext(ctx)
+
+			now := l.now().UTC()
+			day := now.Format(time.DateOnly)
+			key := fmt.Sprintf("quota:create:%s:%s", quotaKey(cfg, r), day)
+			reset := now.Truncate(24 * time.Hour).Add(24 * time.Hour).Sub(now)
+
+			used, err := l.store.Incr(ctx, key, 1, reset+time.Hour)
+			if err != nil {
+				logger.Warn("quota store unavailable", "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set("X-Quota-Limit", strconv.FormatInt(quota, 10))
+			h.Set("X-Quota-Remaining", strconv.FormatInt(max(quota-used, 0), 10))
+			h.Set("X-Quota-Reset", strconv.Itoa(seconds(reset)))
+			if used > quota {
+				l.refund(ctx, key)
+				rateLimited.WithLabelValues("daily_quota").Inc()
+				h.Set("Retry-After", strconv.Itoa(max(seconds(reset), 1)))
+				response.Json(w, map[string]string{"error": "daily quota exceeded"}, http.StatusTooManyRequests)
+				return
+			}
+
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sw, r)
+			if sw.status >= http.StatusBadRequest {
+				l.refund(ctx, key)
+			}
+		})
+	}
+}
+
+func (l *Limiter) refund(ctx context.Context, key string) {
+	if _, err := l.store.Incr(context.WithoutCancel(ctx), key, -1, 0); err != nil {
+		l.log.Warn("failed to refund quota", "key", key, "error", err)
+	}
+}
+
+// clientKey определяет клиента: по известному API ключу или по IP.
+// Заголовки X-User-ID и X-Tenant-ID никто не проверяет, поэтому им не верим.
+func clientKey(cfg *Config, r *http.Request) string {
+	if apiKey, _, ok := cfg.apiKey(r); ok {
+		// Сам ключ в хранилище не попадает
+		sum := sha256.Sum256([]byte(apiKey))
+		return "key:" + hex.EncodeToString(sum[:8])
+	}
+	return ipKey(r)
+}
+
+// quotaKey - рабочее пространство API ключа, без ключа - IP клиента
+func quotaKey(cfg *Config, r *http.Request) string {
+	if _, workspace, ok := cfg.apiKey(r); ok {
+		return "workspace:" + workspace
+	}
+	return ipKey(r)
+}
+
+// ipKey - адрес клиента. X-Forwarded-For учитывается только от доверенных
+// прокси (middleware.RealIP), после него порта в RemoteAddr может не быть.
+func ipKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// apiKey возвращает ключ из заголовка и его рабочее пространство, если ключ известен
+func (c *Config) apiKey(r *http.Request) (apiKey, workspace string, ok bool) {
+	if c.KeyHeader == "" {
+		return "", "", false
+	}
+	apiKey = r.Header.Get(c.KeyHeader)
+	if apiKey == "" {
+		return "", "", false
+	}
+	for _, entry := range c.APIKeys {
+		ws, key, _ := strings.Cut(entry, ":")
+		if subtle.ConstantTimeCompare([]byte(key), []byte(apiKey)) == 1 {
+			workspace, ok = ws, true
+		}
+	}
+	return apiKey, workspace, ok
+}
+
+// ValidAPIKey проверяет формат записи api_keys
+func ValidAPIKey(entry string) bool {
+	workspace, key, ok := strings.Cut(entry, ":")
+	return ok && workspace != "" && key != ""
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
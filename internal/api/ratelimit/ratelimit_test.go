@@ -0,0 +1,162 @@
+package ratelimit
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ctxLog "github.com/SteepTaq/todo_project/pkg/context"
+	"github.com/SteepTaq/todo_project/pkg/middleware"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig() Config {
+	return Config{
+		Enabled:   true,
+		KeyHeader: "X-API-Key",
+		APIKeys:   []string{"acme:a", "acme:b", "globex:c"},
+		Groups: map[string]Limit{
+			"tasks": {Requests: 60, Per: time.Minute, Burst: 2},
+		},
+		DailyCreateQuota: 2,
+	}
+}
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func do(h http.Handler, apiKey string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+var ok = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+func TestTokenBucket(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	h := NewWithStore(testConfig(), store, discard).Middleware("tasks")(ok)
+
+	rec := do(h, "a")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", rec.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, http.StatusOK, do(h, "a").Code)
+
+	rec = do(h, "a")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+	assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+
+	// У другого ключа своя корзина, а по IP клиенты без ключа делят одну
+	assert.Equal(t, http.StatusOK, do(h, "b").Code)
+	assert.Equal(t, http.StatusOK, do(h, "").Code)
+	assert.Equal(t, http.StatusOK, do(h, "").Code)
+	// Неизвестный ключ не дает новой корзины
+	assert.Equal(t, http.StatusTooManyRequests, do(h, "random").Code)
+
+	now = now.Add(time.Second)
+	assert.Equal(t, http.StatusOK, do(h, "a").Code)
+	assert.Equal(t, http.StatusTooManyRequests, do(h, "a").Code)
+}
+
+func TestRedisStoreSharedAcrossReplicas(t *testing.T) {
+	mr := miniredis.RunT(t)
+	newReplica := func() http.Handler {
+		client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		t.Cleanup(func() { client.Close() })
+		return NewWithStore(testConfig(), NewRedisStore(client), discard).Middleware("tasks")(ok)
+	}
+	first, second := newReplica(), newReplica()
+
+	assert.Equal(t, http.StatusOK, do(first, "a").Code)
+	assert.Equal(t, http.StatusOK, do(second, "a").Code)
+	rec := do(first, "a")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+
+	// Redis недоступен - запросы пропускаются
+	mr.Close()
+	assert.Equal(t, http.StatusOK, do(second, "a").Code)
+}
+
+func TestDailyQuota(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	limiter := NewWithStore(testConfig(), NewRedisStore(client), discard)
+
+	fail := false
+	h := limiter.DailyQuota()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	create := func(apiKey, tenant string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/create", strings.NewReader("{}"))
+		req.Header.Set("X-API-Key", apiKey)
+		rec := httptest.NewRecorder()
+		ctx := ctxLog.WithRequestInfo(req.Context(), ctxLog.RequestInfo{TenantID: tenant})
+		h.ServeHTTP(rec, req.WithContext(ctx))
+		return rec
+	}
+
+	// Неуспешное создание квоту не расходует
+	fail = true
+	assert.Equal(t, http.StatusBadRequest, create("a", "acme").Code)
+	fail = false
+
+	assert.Equal(t, http.StatusCreated, create("a", "acme").Code)
+	rec := create("a", "acme")
+	require.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "0", rec.Header().Get("X-Quota-Remaining"))
+
+	rec = create("a", "acme")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+	// Квота общая для ключей рабочего пространства, непроверенный X-Tenant-ID ее не меняет
+	assert.Equal(t, http.StatusTooManyRequests, create("b", "acme").Code)
+	assert.Equal(t, http.StatusTooManyRequests, create("a", "globex").Code)
+	assert.Equal(t, http.StatusCreated, create("c", "acme").Code)
+}
+
+func TestForwardedForFromUntrustedClient(t *testing.T) {
+	trusted, err := middleware.ParseTrustedProxies([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+	h := middleware.RealIP(trusted)(NewWithStore(testConfig(), NewMemoryStore(), discard).Middleware("tasks")(ok))
+	get := func(peer, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = peer
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Клиент напрямую: подмена X-Forwarded-For не дает новой корзины
+	assert.Equal(t, http.StatusOK, get("203.0.113.1:1000", "1.1.1.1"))
+	assert.Equal(t, http.StatusOK, get("203.0.113.1:1000", "1.1.1.2"))
+	assert.Equal(t, http.StatusTooManyRequests, get("203.0.113.1:1000", "1.1.1.3"))
+
+	// Через доверенный прокси клиент - первый недоверенный адрес справа,
+	// подставленные им самим адреса левее не учитываются
+	assert.Equal(t, http.StatusOK, get("10.0.0.1:1000", "9.9.9.9, 198.51.100.7"))
+	assert.Equal(t, http.StatusOK, get("10.0.0.1:1000", "8.8.8.8, 198.51.100.7"))
+	assert.Equal(t, http.StatusTooManyRequests, get("10.0.0.1:1000", "198.51.100.7"))
+	assert.Equal(t, http.StatusOK, get("10.0.0.1:1000", "198.51.100.8"))
+}
@@ -0,0 +1,192 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// MemoryStore хранит лимиты в памяти процесса: у каждой реплики API они свои
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	counters  map[string]*counter
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// Когда корзина наполнится и ее можно забыть
+	full time.Time
+}
+
+type counter struct {
+	value   int64
+	expires time.Time
+}
+
+const sweepInterval = time.Minute
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:  make(map[string]*bucket),
+		counters: make(map[string]*counter),
+		now:      time.Now,
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Decision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	burst := float64(limit.burst())
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		s.buckets[key] = b
+	}
+	b.tokens, b.last = refill(b.tokens, now.Sub(b.last), limit.rate(), burst), now
+
+	d := take(&b.tokens, limit.rate(), burst)
+	b.full = now.Add(d.Reset)
+	return d, nil
+}
+
+func (s *MemoryStore) Incr(_ context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	c, ok := s.counters[key]
+	if !ok {
+		c = &counter{}
+		s.counters[key] = c
+	}
+	c.value += delta
+	if ttl > 0 {
+		c.expires = now.Add(ttl)
+	}
+	return c.value, nil
+}
+
+// sweep удаляет наполнившиеся корзины и истекшие счетчики
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if now.After(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+	for key, c := range s.counters {
+		if !c.expires.IsZero() && now.After(c.expires) {
+			delete(s.counters, key)
+		}
+	}
+}
+
+func refill(tokens float64, elapsed time.Duration, rate, burst float64) float64 {
+	return math.Min(burst, tokens+max(elapsed.Seconds(), 0)*rate)
+}
+
+// take берет токен из корзины, если он есть
+func take(tokens *float64, rate, burst float64) Decision {
+	d := Decision{Allowed: *tokens >= 1}
+	if d.Allowed {
+		*tokens--
+	} else {
+		d.RetryAfter = durationFor(1-*tokens, rate)
+	}
+	d.Remaining = int(*tokens)
+	d.Reset = durationFor(burst-*tokens, rate)
+	return d
+}
+
+func durationFor(tokens, rate float64) time.Duration {
+	return time.Duration(tokens / rate * float64(time.Second))
+}
+
+// RedisStore хранит лимиты в Redis, общие для всех реплик API
+type RedisStore struct {
+	client *redis.Client
+}
+
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// Корзина обновляется атомарно в скрипте. Время берется у Redis, чтобы
+// расхождение часов реплик не влияло на лимит.
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) + tonumber(t[2]) / 1000000
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) / rate * 1000) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+func (s *RedisStore) Take(ctx context.Context, key string, limit Limit) (Decision, error) {
+	rate, burst := limit.rate(), float64(limit.burst())
+	res, err := takeScript.Run(ctx, s.client, []string{key}, rate, burst).Slice()
+	if err != nil {
+		return Decision{}, fmt.Errorf("failed to take token from Redis: %w", err)
+	}
+	if len(res) != 2 {
+		return Decision{}, fmt.Errorf("unexpected rate limit script result: %v", res)
+	}
+	allowed, _ := res[0].(int64)
+	str, _ := res[1].(string)
+	tokens, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return Decision{}, fmt.Errorf("unexpected rate limit script result: %w", err)
+	}
+
+	d := Decision{
+		Allowed:   allowed == 1,
+		Remaining: int(tokens),
+		Reset:     durationFor(burst-tokens, rate),
+	}
+	if !d.Allowed {
+		d.RetryAfter = durationFor(1-tokens, rate)
+	}
+	return d, nil
+}
+
+func (s *RedisStore) Incr(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	pipe := s.client.TxPipeline()
+	incr := pipe.IncrBy(ctx, key, delta)
+	if ttl > 0 {
+		pipe.Expire(ctx, key, ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to update counter in Redis: %w", err)
+	}
+	return incr.Val(), nil
+}
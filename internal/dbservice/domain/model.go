@@ -6,16 +6,50 @@ import (
 )
 
 type Task struct {
-	ID          string    `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Status      string    `json:"status"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at,omitempty"`
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Status      string     `json:"status"`
+	Project     string     `json:"project"`
+	Assignee    string     `json:"assignee,omitempty"` // Исполнитель (email), пусто - не назначен
+	DueAt       *time.Time `json:"due_at,omitempty"`   // Срок выполнения, nil - без срока
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at,omitempty"`
+}
+
+// TaskCursor - позиция в списке задач, упорядоченном по (created_at, id)
+type TaskCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+type ChangeType string
+
+const (
+	TaskCreated ChangeType = "created"
+	TaskUpdated ChangeType = "updated"
+	TaskDeleted ChangeType = "deleted"
+)
+
+// TaskChange - изменение задачи, которое рассылается подписчикам WatchTasks
+type TaskChange struct {
+	Type       ChangeType
+	Task       Task
+	OccurredAt time.Time
+}
+
+// TaskFilter отбирает изменения. Пустые поля означают "без ограничений".
+type TaskFilter struct {
+	Statuses []string
+	IDs      []string
 }
 
 var (
-	ErrTaskNotFound  = errors.New("task not found")
-	ErrInvalidInput  = errors.New("invalid input")
-	ErrTasksNotFound = errors.New("tasks not found")
+	ErrTaskNotFound     = errors.New("task not found")
+	ErrInvalidInput     = errors.New("invalid input")
+	ErrTasksNotFound    = errors.New("tasks not found")
+	ErrWatchTooSlow     = errors.New("watcher is too slow")
+	ErrCacheUnavailable = errors.New("cache is unavailable")
+	// ErrCacheMiss - в кеше нет записи. В отличие от ErrTaskNotFound ничего не говорит о хранилище.
+	ErrCacheMiss = errors.New("cache miss")
 )
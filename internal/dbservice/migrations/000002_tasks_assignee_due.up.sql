@@ -0,0 +1,4 @@
+-- Исполнитель и срок задачи: по ним воркер рассылает письма о назначении и просрочке
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS assignee TEXT NOT NULL DEFAULT ''
+    CHECK (char_length(assignee) <= 255);
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS due_at TIMESTAMPTZ;
@@ -0,0 +1,3 @@
+-- Проект задачи: по нему фильтруются потоки изменений и доски
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS project TEXT NOT NULL DEFAULT ''
+    CHECK (char_length(project) <= 100);
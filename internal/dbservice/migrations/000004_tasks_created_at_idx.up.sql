@@ -0,0 +1,2 @@
+-- Индекс для постраничного обхода задач в StreamTasks
+CREATE INDEX IF NOT EXISTS idx_tasks_created_at_id ON tasks(created_at, id);
@@ -0,0 +1,166 @@
+// Package migrations содержит схемы хранилищ db сервиса. SQL для Postgres лежит
+// в корне каталога, для SQLite - в sqlite/. Файлы встроены в бинарник, поэтому
+// миграции применяются из любого рабочего каталога.
+package migrations
+
+import (
+	"cmp"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"slices"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	// Драйверы для New: postgres://... и sqlite://...
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+var (
+	//go:embed *.sql
+	postgresFS embed.FS
+	//go:embed sqlite/*.sql
+	sqliteFS embed.FS
+)
+
+// Set - встроенные миграции одного хранилища
+type Set struct {
+	fsys fs.FS
+	dir  string
+}
+
+var (
+	Postgres = Set{fsys: postgresFS, dir: "."}
+	// SQLite применяются при открытии базы
+	SQLite = Set{fsys: sqliteFS, dir: "sqlite"}
+)
+
+// Migration - пара файлов миграции одной версии
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Source - источник миграций для golang-migrate
+func (s Set) Source() (source.Driver, error) {
+	src, err := iofs.New(s.fsys, s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	return src, nil
+}
+
+// New создает мигратор для базы databaseURL, например postgres://... или sqlite://...
+func (s Set) New(databaseURL string) (*migrate.Migrate, error) {
+	src, err := s.Source()
+	if err != nil {
+		return nil, err
+	}
+	m, err := migrate.NewWithSourceInstance("iofs", src, databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migrate instance: %w", err)
+	}
+	return m, nil
+}
+
+// List возвращает миграции по возрастанию версии
+func (s Set) List() ([]Migration, error) {
+	entries, err := fs.ReadDir(s.fsys, s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+	byVersion := make(map[uint]*Migration)
+	for _, entry := range entries {
+		parsed, err := source.Parse(entry.Name())
+		if err != nil {
+			continue
+		}
+		data, err := fs.ReadFile(s.fsys, path.Join(s.dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+		m, ok := byVersion[parsed.Version]
+		if !ok {
+			m = &Migration{Version: parsed.Version, Name: parsed.Identifier}
+			byVersion[parsed.Version] = m
+		}
+		switch parsed.Direction {
+		case source.Up:
+			m.Up = string(data)
+		case source.Down:
+			m.Down = string(data)
+		}
+	}
+
+	list := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		list = append(list, *m)
+	}
+	slices.SortFunc(list, func(a, b Migration) int { return cmp.Compare(a.Version, b.Version) })
+	return list, nil
+}
+
+// Step - миграция, которую нужно применить (Up) или откатить
+type Step struct {
+	Migration Migration
+	Up        bool
+}
+
+// SQL - текст миграции в направлении шага
+func (s Step) SQL() string {
+	if s.Up {
+		return s.Migration.Up
+	}
+	return s.Migration.Down
+}
+
+// Plan - шаги перехода с версии current на target. 0 - ни одной примененной миграции.
+func Plan(list []Migration, current, target uint) []Step {
+	var steps []Step
+	if target >= current {
+		for _, m := range list {
+			if m.Version > current && m.Version <= target {
+				steps = append(steps, Step{Migration: m, Up: true})
+			}
+		}
+		return steps
+	}
+	for _, m := range slices.Backward(list) {
+		if m.Version <= current && m.Version > target {
+			steps = append(steps, Step{Migration: m})
+		}
+	}
+	return steps
+}
+
+// Up применяет все новые миграции. Несколько реплик, стартующих одновременно,
+// ждут друг друга на advisory lock базы (pg_advisory_lock у драйвера postgres),
+// но не дольше lockTimeout.
+func (s Set) Up(databaseURL string, lockTimeout time.Duration, log *slog.Logger) error {
+	m, err := s.New(databaseURL)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	m.LockTimeout = lockTimeout
+
+	start := time.Now()
+	if err := m.Up(); err != nil {
+		if errors.Is(err, migrate.ErrNoChange) {
+			log.Info("database schema is up to date")
+			return nil
+		}
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+	version, _, _ := m.Version()
+	log.Info("migrations applied", "version", version, "duration", time.Since(start))
+	return nil
+}
@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetsMatch(t *testing.T) {
+	postgres, err := Postgres.List()
+	require.NoError(t, err)
+	sqlite, err := SQLite.List()
+	require.NoError(t, err)
+
+	// Схемы хранилищ развиваются вместе: версии и имена совпадают
+	require.Len(t, sqlite, len(postgres))
+	for i, m := range postgres {
+		assert.Equal(t, m.Version, sqlite[i].Version)
+		assert.Equal(t, m.Name, sqlite[i].Name)
+		for _, m := range []Migration{m, sqlite[i]} {
+			assert.NotEmpty(t, m.Up, "%d up", m.Version)
+			assert.NotEmpty(t, m.Down, "%d down", m.Version)
+		}
+	}
+}
+
+func TestPlan(t *testing.T) {
+	list := []Migration{{Version: 1}, {Version: 2}, {Version: 5}}
+	versions := func(steps []Step) []uint {
+		var vs []uint
+		for _, s := range steps {
+			vs = append(vs, s.Migration.Version)
+		}
+		return vs
+	}
+
+	up := Plan(list, 0, 5)
+	assert.Equal(t, []uint{1, 2, 5}, versions(up))
+	assert.True(t, up[0].Up)
+
+	down := Plan(list, 5, 1)
+	assert.Equal(t, []uint{5, 2}, versions(down))
+	assert.False(t, down[0].Up)
+
+	assert.Empty(t, Plan(list, 2, 2))
+}
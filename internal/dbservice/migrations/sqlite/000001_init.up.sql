@@ -0,0 +1,14 @@
+-- Время хранится текстом в UTC с микросекундами фиксированной ширины,
+-- чтобы сравнение строк совпадало с порядком времени
+CREATE TABLE tasks (
+    id TEXT PRIMARY KEY,
+    title TEXT NOT NULL CHECK (length(title) <= 255),
+    description TEXT CHECK (length(description) <= 1000),
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'in_progress', 'completed')),
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    updated_at TEXT
+);
+
+CREATE INDEX idx_tasks_status ON tasks(status);
+CREATE INDEX idx_tasks_title ON tasks(title);
@@ -0,0 +1,2 @@
+ALTER TABLE tasks DROP COLUMN due_at;
+ALTER TABLE tasks DROP COLUMN assignee;
@@ -0,0 +1,4 @@
+-- Исполнитель и срок задачи: по ним воркер рассылает письма о назначении и просрочке
+ALTER TABLE tasks ADD COLUMN assignee TEXT NOT NULL DEFAULT ''
+    CHECK (length(assignee) <= 255);
+ALTER TABLE tasks ADD COLUMN due_at TEXT;
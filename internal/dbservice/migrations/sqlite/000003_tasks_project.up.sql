@@ -0,0 +1,3 @@
+-- Проект задачи: по нему фильтруются потоки изменений и доски
+ALTER TABLE tasks ADD COLUMN project TEXT NOT NULL DEFAULT ''
+    CHECK (length(project) <= 100);
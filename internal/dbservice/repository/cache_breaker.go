@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/SteepTaq/todo_project/internal/dbservice/domain"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var cacheCircuitOpen = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "task_cache_circuit_open",
+	Help: "1 if the task cache is bypassed because Redis is unavailable.",
+})
+
+// Cache - кеш задач, за которым следит BreakerCache
+type Cache interface {
+	SetTask(ctx context.Context, task *domain.Task, delta time.Duration) error
+	SetMissing(ctx context.Context, id string) error
+	GetTask(ctx context.Context, id string) (*domain.Task, error)
+	DeleteTask(ctx context.Context, id string) error
+	ListVersion(ctx context.Context) (int64, error)
+	GetTaskList(ctx context.Context, version int64, filter domain.TaskFilter) ([]*domain.Task, error)
+	SetTaskList(ctx context.Context, version int64, filter domain.TaskFilter, tasks []*domain.Task) error
+	Invalidate(ctx context.Context) error
+	// Flush удаляет все закешированные задачи и списки
+	Flush(ctx context.Context) error
+	Ping(ctx context.Context) error
+}
+
+// BreakerCache защищает сервис от недоступного кеша. После threshold ошибок чтения
+// подряд или первой же ошибки записи цепь размыкается: запросы в кеш не идут и сразу
+// возвращают domain.ErrCacheUnavailable, а Run в фоне пингует кеш и замыкает цепь,
+// когда он снова отвечает.
+//
+// Пока цепь разомкнута, изменения задач не попадают в кеш, поэтому перед
+// замыканием кеш очищается целиком.
+type BreakerCache struct {
+	cache     Cache
+	threshold int
+	// Таймаут проверки кеша в Ping и Run
+	timeout time.Duration
+	log     *slog.Logger
+
+	mu       sync.Mutex
+	failures int
+	open     bool
+	// Кеш мог устареть: была пропущена или не удалась запись
+	dirty bool
+}
+
+func NewBreakerCache(cache Cache, threshold int, timeout time.Duration, logger *slog.Logger) *BreakerCache {
+	return &BreakerCache{
+		cache:     cache,
+		threshold: max(threshold, 1),
+		timeout:   timeout,
+		log:       logger.With("component", "cache_breaker"),
+	}
+}
+
+func (c *BreakerCache) SetTask(ctx context.Context, task *domain.Task, delta time.Duration) error {
+	return c.write(func() error { return c.cache.SetTask(ctx, task, delta) })
+}
+
+// SetMissing, как и SetTaskList, не влияет на свежесть кеша: устаревшую
+// отрицательную запись перезапишет SetTask при создании задачи
+func (c *BreakerCache) SetMissing(ctx context.Context, id string) error {
+	if c.Degraded() {
+		return domain.ErrCacheUnavailable
+	}
+	err := c.cache.SetMissing(ctx, id)
+	c.recordRead(err)
+	return err
+}
+
+func (c *BreakerCache) GetTask(ctx context.Context, id string) (*domain.Task, error) {
+	if c.Degraded() {
+		return nil, domain.ErrCacheUnavailable
+	}
+	task, err := c.cache.GetTask(ctx, id)
+	c.recordRead(err)
+	return task, err
+}
+
+func (c *BreakerCache) DeleteTask(ctx context.Context, id string) error {
+	return c.write(func() error { return c.cache.DeleteTask(ctx, id) })
+}
+
+func (c *BreakerCache) ListVersion(ctx context.Context) (int64, error) {
+	if c.Degraded() {
+		return 0, domain.ErrCacheUnavailable
+	}
+	version, err := c.cache.ListVersion(ctx)
+	c.recordRead(err)
+	return version, err
+}
+
+func (c *BreakerCache) GetTaskList(ctx context.Context, version int64, filter domain.TaskFilter) ([]*domain.Task, error) {
+	if c.Degraded() {
+		return nil, domain.ErrCacheUnavailable
+	}
+	tasks, err := c.cache.GetTaskList(ctx, version, filter)
+	c.recordRead(err)
+	return tasks, err
+}
+
+// SetTaskList не влияет на свежесть кеша: список под старой версией просто не будет прочитан
+func (c *BreakerCache) SetTaskList(ctx context.Context, version int64, filter domain.TaskFilter, tasks []*domain.Task) error {
+	if c.Degraded() {
+		return domain.ErrCacheUnavailable
+	}
+	err := c.cache.SetTaskList(ctx, version, filter, tasks)
+	c.recordRead(err)
+	return err
+}
+
+func (c *BreakerCache) Invalidate(ctx context.Context) error {
+	return c.write(func() error { return c.cache.Invalidate(ctx) })
+}
+
+func (c *BreakerCache) Flush(ctx context.Context) error {
+	return c.write(func() error { return c.cache.Flush(ctx) })
+}
+
+// Ping для health check. При замкнутой цепи проверяет сам кеш: без трафика
+// ошибки чтения не накапливаются и отказ кеша иначе остался бы незамеченным.
+// Неудачная проверка размыкает цепь, дальше кеш проверяет Run.
+func (c *BreakerCache) Ping(ctx context.Context) error {
+	if c.Degraded() {
+		return domain.ErrCacheUnavailable
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	err := c.cache.Ping(pingCtx)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		c.trip(err)
+	}
+	return err
+}
+
+func (c *BreakerCache) Degraded() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.open
+}
+
+// Run проверяет кеш при старте и затем каждые interval, пока цепь разомкнута
+func (c *BreakerCache) Run(ctx context.Context, interval time.Duration) {
+	probe := func() {
+		probeCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+
+		if err := c.cache.Ping(probeCtx); err != nil {
+			c.trip(err)
+			return
+		}
+
+		c.mu.Lock()
+		dirty := c.dirty
+		c.mu.Unlock()
+		if dirty {
+			if err := c.cache.Flush(probeCtx); err != nil {
+				c.trip(err)
+				return
+			}
+		}
+		c.reset()
+	}
+
+	probe()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if c.Degraded() {
+				probe()
+			}
+		}
+	}
+}
+
+// write выполняет запись, после которой кеш должен совпадать с хранилищем.
+// Если запись не прошла, кеш нельзя читать до очистки.
+func (c *BreakerCache) write(fn func() error) error {
+	c.mu.Lock()
+	if c.open {
+		c.dirty = true
+		c.mu.Unlock()
+		return domain.ErrCacheUnavailable
+	}
+	c.mu.Unlock()
+
+	err := fn()
+	if err != nil {
+		c.mu.Lock()
+		c.dirty = true
+		c.mu.Unlock()
+		c.trip(err)
+	}
+	return err
+}
+
+// recordRead учитывает результат чтения из кеша. Промах - не ошибка кеша.
+func (c *BreakerCache) recordRead(err error) {
+	if err == nil || errors.Is(err, domain.ErrCacheMiss) || errors.Is(err, domain.ErrTaskNotFound) {
+		c.mu.Lock()
+		c.failures = 0
+		c.mu.Unlock()
+		return
+	}
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+
+	c.mu.Lock()
+	c.failures++
+	tripped := c.failures >= c.threshold
+	c.mu.Unlock()
+
+	if tripped {
+		c.trip(err)
+	}
+}
+
+func (c *BreakerCache) trip(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.open {
+		return
+	}
+	c.open = true
+	cacheCircuitOpen.Set(1)
+	c.log.Warn("cache is unavailable, serving from storage", "error", err)
+}
+
+func (c *BreakerCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.failures = 0
+	c.dirty = false
+	if !c.open {
+		return
+	}
+	c.open = false
+	cacheCircuitOpen.Set(0)
+	c.log.Info("cache is available again")
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SteepTaq/todo_project/internal/dbservice/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type flakyCache struct {
+	down    atomic.Bool
+	calls   atomic.Int32
+	flushes atomic.Int32
+}
+
+func (c *flakyCache) err() error {
+	if c.down.Load() {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func (c *flakyCache) SetTask(ctx context.Context, task *domain.Task, delta time.Duration) error {
+	c.calls.Add(1)
+	return c.err()
+}
+
+func (c *flakyCache) SetMissing(ctx context.Context, id string) error {
+	c.calls.Add(1)
+	return c.err()
+}
+
+func (c *flakyCache) GetTask(ctx context.Context, id string) (*domain.Task, error) {
+	c.calls.Add(1)
+	if err := c.err(); err != nil {
+		return nil, err
+	}
+	return nil, domain.ErrCacheMiss
+}
+
+func (c *flakyCache) DeleteTask(ctx context.Context, id string) error {
+	c.calls.Add(1)
+	return c.err()
+}
+
+func (c *flakyCache) ListVersion(ctx context.Context) (int64, error) {
+	c.calls.Add(1)
+	return 0, c.err()
+}
+
+func (c *flakyCache) GetTaskList(ctx context.Context, version int64, filter domain.TaskFilter) ([]*domain.Task, error) {
+	c.calls.Add(1)
+	if err := c.err(); err != nil {
+		return nil, err
+	}
+	return nil, domain.ErrCacheMiss
+}
+
+func (c *flakyCache) SetTaskList(ctx context.Context, version int64, filter domain.TaskFilter, tasks []*domain.Task) error {
+	c.calls.Add(1)
+	return c.err()
+}
+
+func (c *flakyCache) Invalidate(ctx context.Context) error {
+	c.calls.Add(1)
+	return c.err()
+}
+
+func (c *flakyCache) Flush(ctx context.Context) error {
+	c.flushes.Add(1)
+	return c.err()
+}
+
+func (c *flakyCache) Ping(ctx context.Context) error {
+	return c.err()
+}
+
+func TestBreakerCache(t *testing.T) {
+	backend := &flakyCache{}
+	cache := NewBreakerCache(backend, 3, time.Second, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go cache.Run(ctx, 10*time.Millisecond)
+
+	// Промахи не считаются ошибками
+	for range 5 {
+		_, err := cache.GetTask(ctx, "1")
+		assert.ErrorIs(t, err, domain.ErrCacheMiss)
+	}
+	assert.False(t, cache.Degraded())
+
+	backend.down.Store(true)
+	for range 2 {
+		_, err := cache.GetTask(ctx, "1")
+		assert.Error(t, err)
+	}
+	assert.False(t, cache.Degraded())
+	// Неудачная запись размыкает цепь сразу: в кеше могли остаться устаревшие данные
+	assert.Error(t, cache.SetTask(ctx, &domain.Task{ID: "1"}, time.Millisecond))
+	require.True(t, cache.Degraded())
+	assert.ErrorIs(t, cache.Ping(ctx), domain.ErrCacheUnavailable)
+
+	// Разомкнутая цепь не ходит в кеш
+	calls := backend.calls.Load()
+	_, err := cache.GetTask(ctx, "1")
+	assert.ErrorIs(t, err, domain.ErrCacheUnavailable)
+	assert.Equal(t, calls, backend.calls.Load())
+
+	backend.down.Store(false)
+	assert.Eventually(t, func() bool { return !cache.Degraded() }, time.Second, 5*time.Millisecond)
+	assert.NoError(t, cache.Ping(ctx))
+	assert.Equal(t, int32(1), backend.flushes.Load())
+
+	// Без трафика отказ кеша обнаруживает health check
+	backend.down.Store(true)
+	assert.Error(t, cache.Ping(ctx))
+	assert.True(t, cache.Degraded())
+}
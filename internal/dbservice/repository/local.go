@@ -0,0 +1,348 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SteepTaq/todo_project/internal/dbservice/domain"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+)
+
+// Сообщения об инвалидации: "<origin> task:<id>", "<origin> lists" или "<origin> *"
+const (
+	listsInvalidation = "lists"
+	flushInvalidation = "*"
+)
+
+type localEntry struct {
+	task    *domain.Task
+	missing bool
+	tasks   []*domain.Task
+	expires time.Time
+}
+
+var (
+	localCacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "local_task_cache_requests_total",
+		Help: "Lookups in the in-process task cache by result (hit or miss).",
+	}, []string{"result"})
+	localCacheEvictions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "local_task_cache_evictions_total",
+		Help: "Entries removed from the in-process task cache by reason (capacity, expired or invalidated).",
+	}, []string{"reason"})
+	localCacheEntries = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "local_task_cache_entries",
+		Help: "Entries in the in-process task cache.",
+	})
+)
+
+// LocalCache - кеш в памяти процесса (L1) перед Redis, не больше maxEntries записей
+// с вытеснением давно не читанных. Изменения публикуются через Redis pub/sub,
+// и другие реплики db сервиса удаляют свои копии.
+// Пока подписка не активна, L1 не используется: можно пропустить инвалидацию.
+type LocalCache struct {
+	remote *RedisRepo
+	ttl    time.Duration
+	origin string
+	log    *slog.Logger
+
+	mu      sync.Mutex
+	synced  bool
+	entries *lru
+	// Версия списков из Redis, nil - не известна
+	version *int64
+	// Растет при каждом вытеснении. Значения, прочитанные из Redis до него,
+	// в L1 не попадают.
+	gen uint64
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+func NewLocalCache(remote *RedisRepo, maxEntries int, ttl time.Duration, logger *slog.Logger) *LocalCache {
+	return &LocalCache{
+		remote:  remote,
+		ttl:     ttl,
+		origin:  uuid.New().String(),
+		log:     logger.With("component", "local_cache"),
+		entries: newLRU(maxEntries),
+	}
+}
+
+func (c *LocalCache) SetTask(ctx context.Context, task *domain.Task, delta time.Duration) error {
+	if err := c.remote.SetTask(ctx, task, delta); err != nil {
+		c.evict("task:" + task.ID)
+		return err
+	}
+	c.store("task:"+task.ID, localEntry{task: task})
+	c.publish(ctx, "task:"+task.ID)
+	return nil
+}
+
+func (c *LocalCache) SetMissing(ctx context.Context, id string) error {
+	if err := c.remote.SetMissing(ctx, id); err != nil {
+		return err
+	}
+	c.store("task:"+id, localEntry{missing: true})
+	return nil
+}
+
+func (c *LocalCache) GetTask(ctx context.Context, id string) (*domain.Task, error) {
+	if e, ok := c.load("task:" + id); ok {
+		if e.missing {
+			return nil, domain.ErrTaskNotFound
+		}
+		return e.task, nil
+	}
+	gen := c.generation()
+	task, err := c.remote.GetTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	c.fill("task:"+id, localEntry{task: task}, gen)
+	return task, nil
+}
+
+// DeleteTask и Invalidate вытесняют L1 и до записи в Redis, и после:
+// чтение, начатое между ними, могло вернуть старое значение
+func (c *LocalCache) DeleteTask(ctx context.Context, id string) error {
+	c.evict("task:" + id)
+	err := c.remote.DeleteTask(ctx, id)
+	c.evict("task:" + id)
+	c.publish(ctx, "task:"+id)
+	return err
+}
+
+func (c *LocalCache) ListVersion(ctx context.Context) (int64, error) {
+	c.mu.Lock()
+	if c.synced && c.version != nil {
+		version := *c.version
+		c.mu.Unlock()
+		return version, nil
+	}
+	gen := c.gen
+	c.mu.Unlock()
+
+	version, err := c.remote.ListVersion(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	if c.synced && c.gen == gen {
+		c.version = &version
+	}
+	c.mu.Unlock()
+	return version, nil
+}
+
+func (c *LocalCache) GetTaskList(ctx context.Context, version int64, filter domain.TaskFilter) ([]*domain.Task, error) {
+	key := taskListKey(version, filter)
+	if e, ok := c.load(key); ok {
+		return e.tasks, nil
+	}
+	gen := c.generation()
+	tasks, err := c.remote.GetTaskList(ctx, version, filter)
+	if err != nil {
+		return nil, err
+	}
+	c.fill(key, localEntry{tasks: tasks}, gen)
+	return tasks, nil
+}
+
+func (c *LocalCache) SetTaskList(ctx context.Context, version int64, filter domain.TaskFilter, tasks []*domain.Task) error {
+	if err := c.remote.SetTaskList(ctx, version, filter, tasks); err != nil {
+		return err
+	}
+	c.store(taskListKey(version, filter), localEntry{tasks: tasks})
+	return nil
+}
+
+func (c *LocalCache) Invalidate(ctx context.Context) error {
+	c.evict(listsInvalidation)
+	err := c.remote.Invalidate(ctx)
+	c.evict(listsInvalidation)
+	c.publish(ctx, listsInvalidation)
+	return err
+}
+
+func (c *LocalCache) Flush(ctx context.Context) error {
+	c.evict(flushInvalidation)
+	err := c.remote.Flush(ctx)
+	c.evict(flushInvalidation)
+	c.publish(ctx, flushInvalidation)
+	return err
+}
+
+func (c *LocalCache) Ping(ctx context.Context) error {
+	return c.remote.Ping(ctx)
+}
+
+// Run слушает инвалидации от других реплик, пока не завершится контекст.
+// После обрыва подписки L1 очищается: сообщения за это время потеряны.
+func (c *LocalCache) Run(ctx context.Context) {
+	pubsub := c.remote.SubscribeInvalidations(ctx)
+	defer pubsub.Close()
+
+	for {
+		msg, err := pubsub.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if c.setSynced(false) {
+				c.log.Warn("invalidation subscription lost, local cache disabled", "error", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		switch m := msg.(type) {
+		case *redis.Subscription:
+			// go-redis переподписывается сам и присылает Subscription после переподключения
+			if m.Kind == "subscribe" && !c.setSynced(true) {
+				c.log.Info("invalidation subscription established, local cache enabled")
+			}
+		case *redis.Message:
+			origin, key, ok := strings.Cut(m.Payload, " ")
+			if ok && origin != c.origin {
+				c.evict(key)
+			}
+		}
+	}
+}
+
+// setSynced очищает L1 и возвращает прошлое состояние подписки
+func (c *LocalCache) setSynced(synced bool) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prev := c.synced
+	c.synced = synced
+	c.gen++
+	c.evicted("invalidated", c.entries.clear())
+	c.version = nil
+	return prev
+}
+
+func (c *LocalCache) load(key string) (localEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.synced {
+		return localEntry{}, false
+	}
+	e, ok, expired := c.entries.get(key, time.Now())
+	if expired {
+		c.evicted("expired", 1)
+	}
+	if !ok {
+		c.misses.Add(1)
+		localCacheRequests.WithLabelValues("miss").Inc()
+		return localEntry{}, false
+	}
+	c.hits.Add(1)
+	localCacheRequests.WithLabelValues("hit").Inc()
+	return e, true
+}
+
+func (c *LocalCache) generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gen
+}
+
+func (c *LocalCache) store(key string, e localEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.storeLocked(key, e)
+}
+
+// fill кладет в L1 значение, прочитанное из Redis, если с начала чтения
+// ничего не вытеснялось
+func (c *LocalCache) fill(key string, e localEntry, gen uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.gen == gen {
+		c.storeLocked(key, e)
+	}
+}
+
+func (c *LocalCache) storeLocked(key string, e localEntry) {
+	if !c.synced {
+		return
+	}
+	ttl := c.ttl
+	if e.missing {
+		ttl = min(ttl, c.remote.Policy().NegativeTTL)
+	}
+	e.expires = time.Now().Add(ttl)
+	c.evicted("capacity", c.entries.set(key, e))
+}
+
+func (c *LocalCache) evict(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	switch key {
+	case flushInvalidation:
+		c.evicted("invalidated", c.entries.clear())
+		c.version = nil
+	case listsInvalidation:
+		c.evicted("invalidated", c.entries.removePrefix("tasks:list:"))
+		c.version = nil
+	default:
+		if c.entries.remove(key) {
+			c.evicted("invalidated", 1)
+		}
+	}
+}
+
+// evicted учитывает удаленные записи, вызывается под c.mu
+func (c *LocalCache) evicted(reason string, n int) {
+	if n > 0 {
+		c.evictions.Add(uint64(n))
+		localCacheEvictions.WithLabelValues(reason).Add(float64(n))
+	}
+	localCacheEntries.Set(float64(c.entries.len()))
+}
+
+type LocalCacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Entries   int
+}
+
+func (c *LocalCache) Stats() LocalCacheStats {
+	c.mu.Lock()
+	entries := c.entries.len()
+	c.mu.Unlock()
+
+	return LocalCacheStats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		Entries:   entries,
+	}
+}
+
+func (c *LocalCache) publish(ctx context.Context, key string) {
+	if err := c.remote.PublishInvalidation(ctx, c.origin+" "+key); err != nil {
+		c.log.Warn("failed to publish invalidation", "key", key, "error", err)
+	}
+}
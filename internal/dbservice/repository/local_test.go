@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SteepTaq/todo_project/internal/dbservice/domain"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalCacheInvalidation(t *testing.T) {
+	mr := miniredis.RunT(t)
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Две реплики db сервиса с общим Redis
+	replica := func() *LocalCache {
+		remote, err := NewRedisRepo(mr.Addr(), "", 0, CachePolicy{TTL: time.Minute, NegativeTTL: time.Minute}, time.Second, log)
+		require.NoError(t, err)
+		t.Cleanup(remote.Close)
+		c := NewLocalCache(remote, 100, time.Minute, log)
+		go c.Run(ctx)
+		require.Eventually(t, func() bool {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			return c.synced
+		}, time.Second, 5*time.Millisecond)
+		return c
+	}
+	a, b := replica(), replica()
+
+	require.NoError(t, a.SetTask(ctx, &domain.Task{ID: "1", Title: "old"}, time.Millisecond))
+	task, err := b.GetTask(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, "old", task.Title)
+
+	version, err := b.ListVersion(ctx)
+	require.NoError(t, err)
+	require.NoError(t, b.SetTaskList(ctx, version, domain.TaskFilter{}, []*domain.Task{task}))
+
+	// Правка на реплике a вытесняет копии из L1 реплики b
+	require.NoError(t, a.SetTask(ctx, &domain.Task{ID: "1", Title: "new"}, time.Millisecond))
+	require.NoError(t, a.Invalidate(ctx))
+	assert.Eventually(t, func() bool {
+		task, err := b.GetTask(ctx, "1")
+		return err == nil && task.Title == "new"
+	}, time.Second, 5*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		v, err := b.ListVersion(ctx)
+		return err == nil && v != version
+	}, time.Second, 5*time.Millisecond)
+
+	newVersion, err := b.ListVersion(ctx)
+	require.NoError(t, err)
+	_, err = b.GetTaskList(ctx, newVersion, domain.TaskFilter{})
+	assert.ErrorIs(t, err, domain.ErrCacheMiss)
+
+	require.NoError(t, a.DeleteTask(ctx, "1"))
+	assert.Eventually(t, func() bool {
+		_, err := b.GetTask(ctx, "1")
+		return err == domain.ErrCacheMiss
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestLocalCacheReadDuringWrite(t *testing.T) {
+	mr := miniredis.RunT(t)
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	remote, err := NewRedisRepo(mr.Addr(), "", 0, CachePolicy{TTL: time.Minute, NegativeTTL: time.Minute}, time.Second, log)
+	require.NoError(t, err)
+	defer remote.Close()
+	c := NewLocalCache(remote, 100, time.Minute, log)
+	go c.Run(ctx)
+	require.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.synced
+	}, time.Second, 5*time.Millisecond)
+
+	// Чтения идут, пока задача удаляется, а списки инвалидируются.
+	// Старые значения не должны остаться в L1 после записи.
+	for range 100 {
+		require.NoError(t, remote.SetTask(ctx, &domain.Task{ID: "1"}, time.Millisecond))
+		stop := make(chan struct{})
+		var wg sync.WaitGroup
+		for range 4 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+						c.GetTask(ctx, "1")
+						c.ListVersion(ctx)
+					}
+				}
+			}()
+		}
+
+		require.NoError(t, c.DeleteTask(ctx, "1"))
+		require.NoError(t, c.Invalidate(ctx))
+		close(stop)
+		wg.Wait()
+
+		_, err := c.GetTask(ctx, "1")
+		require.ErrorIs(t, err, domain.ErrCacheMiss)
+		version, err := c.ListVersion(ctx)
+		require.NoError(t, err)
+		remoteVersion, err := remote.ListVersion(ctx)
+		require.NoError(t, err)
+		require.Equal(t, remoteVersion, version)
+	}
+}
+
+func TestRedisRepoEntries(t *testing.T) {
+	mr := miniredis.RunT(t)
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.Background()
+
+	repo, err := NewRedisRepo(mr.Addr(), "", 0, CachePolicy{TTL: time.Minute, NegativeTTL: time.Minute}, time.Second, log)
+	require.NoError(t, err)
+	defer repo.Close()
+
+	_, err = repo.GetTask(ctx, "1")
+	assert.ErrorIs(t, err, domain.ErrCacheMiss)
+
+	require.NoError(t, repo.SetMissing(ctx, "1"))
+	_, err = repo.GetTask(ctx, "1")
+	assert.ErrorIs(t, err, domain.ErrTaskNotFound)
+
+	require.NoError(t, repo.SetTask(ctx, &domain.Task{ID: "1"}, time.Hour))
+	_, err = repo.GetTask(ctx, "1")
+	assert.NoError(t, err)
+
+	// Чтение из хранилища дольше ttl: запись почти всегда обновляется заранее
+	policy := repo.Policy()
+	policy.EarlyRefreshBeta = 1
+	repo.SetPolicy(policy)
+	misses := 0
+	for range 100 {
+		if _, err := repo.GetTask(ctx, "1"); err != nil {
+			require.ErrorIs(t, err, domain.ErrCacheMiss)
+			misses++
+		}
+	}
+	assert.Greater(t, misses, 90)
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"container/list"
+	"strings"
+	"time"
+)
+
+type lruItem struct {
+	key   string
+	entry localEntry
+}
+
+// lru - ограниченный по числу записей кеш с вытеснением давно не читанных.
+// Не потокобезопасен, синхронизацию делает LocalCache.
+type lru struct {
+	max   int
+	order *list.List
+	items map[string]*list.Element
+}
+
+func newLRU(max int) *lru {
+	return &lru{
+		max:   max,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// get возвращает запись и признак, что она была, но истекла
+func (l *lru) get(key string, now time.Time) (localEntry, bool, bool) {
+	el, ok := l.items[key]
+	if !ok {
+		return localEntry{}, false, false
+	}
+	item := el.Value.(*lruItem)
+	if now.After(item.entry.expires) {
+		l.removeElement(el)
+		return localEntry{}, false, true
+	}
+	l.order.MoveToFront(el)
+	return item.entry, true, false
+}
+
+// set добавляет запись и возвращает, сколько записей вытеснено из-за лимита
+func (l *lru) set(key string, e localEntry) int {
+	if el, ok := l.items[key]; ok {
+		el.Value.(*lruItem).entry = e
+		l.order.MoveToFront(el)
+		return 0
+	}
+	l.items[key] = l.order.PushFront(&lruItem{key: key, entry: e})
+
+	evicted := 0
+	for l.max > 0 && l.order.Len() > l.max {
+		l.removeElement(l.order.Back())
+		evicted++
+	}
+	return evicted
+}
+
+func (l *lru) remove(key string) bool {
+	el, ok := l.items[key]
+	if ok {
+		l.removeElement(el)
+	}
+	return ok
+}
+
+func (l *lru) removePrefix(prefix string) int {
+	removed := 0
+	for key, el := range l.items {
+		if strings.HasPrefix(key, prefix) {
+			l.removeElement(el)
+			removed++
+		}
+	}
+	return removed
+}
+
+func (l *lru) clear() int {
+	n := l.order.Len()
+	l.order.Init()
+	clear(l.items)
+	return n
+}
+
+func (l *lru) len() int {
+	return l.order.Len()
+}
+
+func (l *lru) removeElement(el *list.Element) {
+	l.order.Remove(el)
+	delete(l.items, el.Value.(*lruItem).key)
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU(t *testing.T) {
+	now := time.Now()
+	fresh := localEntry{expires: now.Add(time.Minute)}
+	l := newLRU(2)
+
+	assert.Equal(t, 0, l.set("a", fresh))
+	assert.Equal(t, 0, l.set("b", fresh))
+	// Чтение делает "a" свежее "b", и при переполнении вытесняется "b"
+	_, ok, _ := l.get("a", now)
+	assert.True(t, ok)
+	assert.Equal(t, 1, l.set("c", fresh))
+
+	_, ok, _ = l.get("b", now)
+	assert.False(t, ok)
+	_, ok, _ = l.get("a", now)
+	assert.True(t, ok)
+
+	_, ok, expired := l.get("c", now.Add(2*time.Minute))
+	assert.False(t, ok)
+	assert.True(t, expired)
+	assert.Equal(t, 1, l.len())
+}
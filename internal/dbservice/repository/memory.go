@@ -0,0 +1,333 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/SteepTaq/todo_project/internal/dbservice/domain"
+)
+
+// MemoryRepo хранит задачи в памяти процесса, для тестов и демонстраций.
+// Повторяет поведение Postgres: проверки схемы, округление времени до
+// микросекунд, порядок (created_at, id) и domain.ErrTaskNotFound.
+type MemoryRepo struct {
+	mu    sync.RWMutex
+	tasks map[string]domain.Task
+	log   *slog.Logger
+}
+
+func NewMemoryRepo(logger *slog.Logger) *MemoryRepo {
+	return &MemoryRepo{
+		tasks: make(map[string]domain.Task),
+		log:   logger.With("component", "memory_repo"),
+	}
+}
+
+func (r *MemoryRepo) Close() {}
+
+func (r *MemoryRepo) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func (r *MemoryRepo) CountTasksByStatus(ctx context.Context) (map[string]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, task := range r.tasks {
+		counts[task.Status]++
+	}
+	return counts, nil
+}
+
+func (r *MemoryRepo) GetTaskByID(ctx context.Context, id string) (*domain.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	task, ok := r.tasks[id]
+	if !ok {
+		return nil, domain.ErrTaskNotFound
+	}
+	return &task, nil
+}
+
+func (r *MemoryRepo) GetAllTasks(ctx context.Context) ([]*domain.Task, error) {
+	return r.list(nil, -1), nil
+}
+
+// ListTasksPage возвращает до limit задач после курсора в порядке (created_at, id).
+// nil курсор - с начала списка.
+func (r *MemoryRepo) ListTasksPage(ctx context.Context, after *domain.TaskCursor, limit int) ([]*domain.Task, error) {
+	return r.list(after, limit), nil
+}
+
+// list возвращает задачи после курсора, limit < 0 - все
+func (r *MemoryRepo) list(after *domain.TaskCursor, limit int) []*domain.Task {
+	r.mu.RLock()
+	tasks := make([]*domain.Task, 0, len(r.tasks))
+	for _, task := range r.tasks {
+		if after != nil && compareCursor(&task, after.CreatedAt.Round(time.Microsecond), after.ID) <= 0 {
+			continue
+		}
+		tasks = append(tasks, &task)
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(tasks, func(a, b *domain.Task) int {
+		return compareCursor(a, b.CreatedAt, b.ID)
+	})
+	if limit >= 0 && len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+	return tasks
+}
+
+func compareCursor(task *domain.Task, createdAt time.Time, id string) int {
+	if c := task.CreatedAt.Compare(createdAt); c != 0 {
+		return c
+	}
+	return cmp.Compare(task.ID, id)
+}
+
+func (r *MemoryRepo) CreateTask(ctx context.Context, task *domain.Task) (*domain.Task, error) {
+	created := *task
+	created.CreatedAt = created.CreatedAt.Round(time.Microsecond)
+	created.UpdatedAt = created.UpdatedAt.Round(time.Microsecond)
+	created.DueAt = roundDue(created.DueAt)
+	if created.Status == "" {
+		created.Status = "pending"
+	}
+	if err := checkTask(&created); err != nil {
+		return nil, fmt.Errorf("failed to create task: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tasks[created.ID]; ok {
+		return nil, fmt.Errorf("failed to create task: duplicate id %q", created.ID)
+	}
+	r.tasks[created.ID] = created
+	return &created, nil
+}
+
+func (r *MemoryRepo) UpdateTask(ctx context.Context, task *domain.Task) (*domain.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	updated, ok := r.tasks[task.ID]
+	if !ok {
+		return nil, domain.ErrTaskNotFound
+	}
+	updated.Title = task.Title
+	updated.Description = task.Description
+	updated.Status = task.Status
+	updated.Project = task.Project
+	updated.Assignee = task.Assignee
+	updated.DueAt = roundDue(task.DueAt)
+	updated.UpdatedAt = task.UpdatedAt.Round(time.Microsecond)
+	if err := checkTask(&updated); err != nil {
+		return nil, fmt.Errorf("failed to update task: %w", err)
+	}
+	r.tasks[task.ID] = updated
+	return &updated, nil
+}
+
+func (r *MemoryRepo) DeleteTask(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tasks[id]; !ok {
+		return domain.ErrTaskNotFound
+	}
+	delete(r.tasks, id)
+	return nil
+}
+
+// roundDue копирует срок с точностью Postgres
+func roundDue(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	due := t.Round(time.Microsecond)
+	return &due
+}
+
+// checkTask - ограничения таблицы tasks из миграций
+func checkTask(task *domain.Task) error {
+	switch {
+	case task.ID == "":
+		return fmt.Errorf("id is required")
+	case utf8.RuneCountInString(task.Title) > 255:
+		return fmt.Errorf("title is longer than 255 characters")
+	case utf8.RuneCountInString(task.Description) > 1000:
+		return fmt.Errorf("description is longer than 1000 characters")
+	case utf8.RuneCountInString(task.Project) > 100:
+		return fmt.Errorf("project is longer than 100 characters")
+	case utf8.RuneCountInString(task.Assignee) > 255:
+		return fmt.Errorf("assignee is longer than 255 characters")
+	case !slices.Contains([]string{"pending", "in_progress", "completed"}, task.Status):
+		return fmt.Errorf("invalid status %q", task.Status)
+	}
+	return nil
+}
+
+// MemoryCache - кеш задач в памяти процесса с поведением RedisRepo:
+// ttl записей, запоминание отсутствующих задач и версии списков.
+// Раннее обновление (EarlyRefreshBeta) не поддерживается.
+type MemoryCache struct {
+	policy atomic.Pointer[CachePolicy]
+
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	version int64
+	now     func() time.Time
+}
+
+type memoryEntry struct {
+	task      *domain.Task
+	tasks     []*domain.Task
+	missing   bool
+	expiresAt time.Time
+}
+
+func NewMemoryCache(policy CachePolicy) *MemoryCache {
+	c := &MemoryCache{
+		entries: make(map[string]memoryEntry),
+		now:     time.Now,
+	}
+	c.policy.Store(&policy)
+	return c
+}
+
+// SetPolicy применяет новые ttl к записям, которые кладутся в кеш после вызова
+func (c *MemoryCache) SetPolicy(policy CachePolicy) {
+	c.policy.Store(&policy)
+}
+
+func (c *MemoryCache) Policy() CachePolicy {
+	return *c.policy.Load()
+}
+
+func (c *MemoryCache) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func (c *MemoryCache) SetTask(ctx context.Context, task *domain.Task, delta time.Duration) error {
+	copied := *task
+	c.set("task:"+task.ID, memoryEntry{task: &copied}, c.Policy().TTL)
+	return nil
+}
+
+func (c *MemoryCache) SetMissing(ctx context.Context, id string) error {
+	c.set("task:"+id, memoryEntry{missing: true}, c.Policy().NegativeTTL)
+	return nil
+}
+
+func (c *MemoryCache) GetTask(ctx context.Context, id string) (*domain.Task, error) {
+	entry, ok := c.get("task:" + id)
+	switch {
+	case !ok:
+		return nil, domain.ErrCacheMiss
+	case entry.missing:
+		return nil, domain.ErrTaskNotFound
+	}
+	task := *entry.task
+	return &task, nil
+}
+
+func (c *MemoryCache) DeleteTask(ctx context.Context, id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, "task:"+id)
+	return nil
+}
+
+func (c *MemoryCache) ListVersion(ctx context.Context) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.version, nil
+}
+
+func (c *MemoryCache) GetTaskList(ctx context.Context, version int64, filter domain.TaskFilter) ([]*domain.Task, error) {
+	entry, ok := c.get(taskListKey(version, filter))
+	if !ok {
+		return nil, domain.ErrCacheMiss
+	}
+	return cloneTasks(entry.tasks), nil
+}
+
+func (c *MemoryCache) SetTaskList(ctx context.Context, version int64, filter domain.TaskFilter, tasks []*domain.Task) error {
+	c.set(taskListKey(version, filter), memoryEntry{tasks: cloneTasks(tasks)}, c.Policy().TTL)
+	return nil
+}
+
+// Invalidate делает недействительными все закешированные списки задач
+func (c *MemoryCache) Invalidate(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version++
+	// Списки старых версий больше не читаются
+	maps.DeleteFunc(c.entries, func(key string, _ memoryEntry) bool {
+		return strings.HasPrefix(key, "tasks:list:")
+	})
+	return nil
+}
+
+// Flush удаляет все задачи из кеша и делает недействительными списки
+func (c *MemoryCache) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	clear(c.entries)
+	c.mu.Unlock()
+	return c.Invalidate(ctx)
+}
+
+func (c *MemoryCache) set(key string, entry memoryEntry, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	entry.expiresAt = now.Add(ttl)
+	c.entries[key] = entry
+	// Истекшие записи удаляются при записи, чтобы кеш не рос без ограничений
+	if len(c.entries)%1024 == 0 {
+		maps.DeleteFunc(c.entries, func(_ string, e memoryEntry) bool {
+			return !now.Before(e.expiresAt)
+		})
+	}
+}
+
+func (c *MemoryCache) get(key string) (memoryEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return memoryEntry{}, false
+	}
+	if !c.now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		return memoryEntry{}, false
+	}
+	return entry, true
+}
+
+func cloneTasks(tasks []*domain.Task) []*domain.Task {
+	cloned := make([]*domain.Task, 0, len(tasks))
+	for _, task := range tasks {
+		copied := *task
+		cloned = append(cloned, &copied)
+	}
+	return cloned
+}
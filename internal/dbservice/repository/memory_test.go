@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/SteepTaq/todo_project/internal/dbservice/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	c := NewMemoryCache(CachePolicy{TTL: time.Minute, NegativeTTL: 10 * time.Second})
+	c.now = func() time.Time { return now }
+
+	_, err := c.GetTask(ctx, "1")
+	assert.ErrorIs(t, err, domain.ErrCacheMiss)
+
+	task := &domain.Task{ID: "1", Title: "cached"}
+	require.NoError(t, c.SetTask(ctx, task, time.Millisecond))
+	// Кеш хранит копию
+	task.Title = "changed"
+	got, err := c.GetTask(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, "cached", got.Title)
+
+	require.NoError(t, c.SetMissing(ctx, "2"))
+	_, err = c.GetTask(ctx, "2")
+	assert.ErrorIs(t, err, domain.ErrTaskNotFound)
+
+	// Отсутствие помнится меньше, чем сама задача
+	now = now.Add(30 * time.Second)
+	_, err = c.GetTask(ctx, "2")
+	assert.ErrorIs(t, err, domain.ErrCacheMiss)
+	_, err = c.GetTask(ctx, "1")
+	assert.NoError(t, err)
+	now = now.Add(time.Minute)
+	_, err = c.GetTask(ctx, "1")
+	assert.ErrorIs(t, err, domain.ErrCacheMiss)
+
+	version, err := c.ListVersion(ctx)
+	require.NoError(t, err)
+	filter := domain.TaskFilter{Statuses: []string{"pending", "completed"}}
+	require.NoError(t, c.SetTaskList(ctx, version, filter, []*domain.Task{{ID: "1"}}))
+	list, err := c.GetTaskList(ctx, version, domain.TaskFilter{Statuses: []string{"completed", "pending"}})
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	require.NoError(t, c.Invalidate(ctx))
+	next, err := c.ListVersion(ctx)
+	require.NoError(t, err)
+	assert.NotEqual(t, version, next)
+	_, err = c.GetTaskList(ctx, version, filter)
+	assert.ErrorIs(t, err, domain.ErrCacheMiss)
+}
@@ -8,7 +8,8 @@ import (
 	"time"
 
 	"github.com/SteepTaq/todo_project/internal/dbservice/domain"
-	"github.com/jackc/pgx"
+	"github.com/exaring/otelpgx"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -37,6 +38,7 @@ func NewPostgresRepo(dsn string, maxConns int, maxIdleTime time.Duration, logger
 
 	poolCfg.MaxConns = int32(maxConns)
 	poolCfg.MaxConnIdleTime = maxIdleTime
+	poolCfg.ConnConfig.Tracer = otelpgx.NewTracer()
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -58,8 +60,32 @@ func NewPostgresRepo(dsn string, maxConns int, maxIdleTime time.Duration, logger
 func (r *PostgresRepo) Close() {
 	r.pool.Close()
 }
+
+// Stat возвращает статистику пула соединений для метрик
+func (r *PostgresRepo) Stat() *pgxpool.Stat {
+	return r.pool.Stat()
+}
+
+func (r *PostgresRepo) CountTasksByStatus(ctx context.Context) (map[string]int, error) {
+	rows, err := r.pool.Query(ctx, "SELECT status, count(*) FROM tasks GROUP BY status")
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tasks: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan task count: %w", err)
+		}
+		counts[status] = count
+	}
+	return counts, rows.Err()
+}
 func (r *PostgresRepo) GetTaskByID(ctx context.Context, id string) (*domain.Task, error) {
-	query := `SELECT id, title, description, status, created_at, updated_at 
+	query := `SELECT id, title, description, status, project, assignee, due_at, created_at, updated_at 
               FROM tasks WHERE id = $1`
 
 	row := r.pool.QueryRow(ctx, query, id)
@@ -70,6 +96,9 @@ func (r *PostgresRepo) GetTaskByID(ctx context.Context, id string) (*domain.Task
 		&task.Title,
 		&task.Description,
 		&task.Status,
+		&task.Project,
+		&task.Assignee,
+		&task.DueAt,
 		&task.CreatedAt,
 		&task.UpdatedAt,
 	); err != nil {
@@ -83,7 +112,7 @@ func (r *PostgresRepo) GetTaskByID(ctx context.Context, id string) (*domain.Task
 }
 
 func (r *PostgresRepo) GetAllTasks(ctx context.Context) ([]*domain.Task, error) {
-	rows, err := r.pool.Query(ctx, "SELECT * FROM tasks")
+	rows, err := r.pool.Query(ctx, "SELECT id, title, description, status, project, assignee, due_at, created_at, updated_at FROM tasks")
 	if err != nil {
 		return nil, err
 	}
@@ -92,7 +121,7 @@ func (r *PostgresRepo) GetAllTasks(ctx context.Context) ([]*domain.Task, error)
 	var tasks []*domain.Task
 	for rows.Next() {
 		var task domain.Task
-		err := rows.Scan(&task.ID, &task.Title, &task.Description, &task.Status, &task.CreatedAt, &task.UpdatedAt)
+		err := rows.Scan(&task.ID, &task.Title, &task.Description, &task.Status, &task.Project, &task.Assignee, &task.DueAt, &task.CreatedAt, &task.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -101,16 +130,52 @@ func (r *PostgresRepo) GetAllTasks(ctx context.Context) ([]*domain.Task, error)
 	return tasks, nil
 }
 
+// ListTasksPage возвращает до limit задач после курсора в порядке (created_at, id).
+// nil курсор - с начала списка.
+func (r *PostgresRepo) ListTasksPage(ctx context.Context, after *domain.TaskCursor, limit int) ([]*domain.Task, error) {
+	query := `SELECT id, title, description, status, project, assignee, due_at, created_at, updated_at
+              FROM tasks ORDER BY created_at, id LIMIT $1`
+	args := []any{limit}
+	if after != nil {
+		query = `SELECT id, title, description, status, project, assignee, due_at, created_at, updated_at
+                 FROM tasks WHERE (created_at, id) > ($2, $3)
+                 ORDER BY created_at, id LIMIT $1`
+		args = append(args, after.CreatedAt, after.ID)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := make([]*domain.Task, 0, limit)
+	for rows.Next() {
+		var task domain.Task
+		if err := rows.Scan(&task.ID, &task.Title, &task.Description, &task.Status, &task.Project, &task.Assignee, &task.DueAt, &task.CreatedAt, &task.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan task: %w", err)
+		}
+		tasks = append(tasks, &task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	return tasks, nil
+}
+
 func (r *PostgresRepo) CreateTask(ctx context.Context, task *domain.Task) (*domain.Task, error) {
-	query := `INSERT INTO tasks (id, title, description, status, created_at, updated_at) 
-              VALUES ($1, $2, $3, $4, $5, $6)
-              RETURNING id, title, description, status, created_at, updated_at`
+	query := `INSERT INTO tasks (id, title, description, status, project, assignee, due_at, created_at, updated_at) 
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+              RETURNING id, title, description, status, project, assignee, due_at, created_at, updated_at`
 
 	row := r.pool.QueryRow(ctx, query,
 		task.ID,
 		task.Title,
 		task.Description,
 		task.Status,
+		task.Project,
+		task.Assignee,
+		task.DueAt,
 		task.CreatedAt,
 		task.UpdatedAt,
 	)
@@ -121,6 +186,9 @@ func (r *PostgresRepo) CreateTask(ctx context.Context, task *domain.Task) (*doma
 		&createdTask.Title,
 		&createdTask.Description,
 		&createdTask.Status,
+		&createdTask.Project,
+		&createdTask.Assignee,
+		&createdTask.DueAt,
 		&createdTask.CreatedAt,
 		&createdTask.UpdatedAt,
 	); err != nil {
@@ -130,10 +198,13 @@ func (r *PostgresRepo) CreateTask(ctx context.Context, task *domain.Task) (*doma
 	return &createdTask, nil
 }
 func (r *PostgresRepo) UpdateTask(ctx context.Context, tasks *domain.Task) (*domain.Task, error) {
-	row := r.pool.QueryRow(ctx, "UPDATE tasks SET title = $1, description = $2, status = $3, updated_at = $4 WHERE id = $5 RETURNING id, title, description, status, created_at, updated_at",
+	row := r.pool.QueryRow(ctx, "UPDATE tasks SET title = $1, description = $2, status = $3, project = $4, assignee = $5, due_at = $6, updated_at = $7 WHERE id = $8 RETURNING id, title, description, status, project, assignee, due_at, created_at, updated_at",
 		tasks.Title,
 		tasks.Description,
 		tasks.Status,
+		tasks.Project,
+		tasks.Assignee,
+		tasks.DueAt,
 		tasks.UpdatedAt,
 		tasks.ID)
 	var updatedTask domain.Task
@@ -142,15 +213,31 @@ func (r *PostgresRepo) UpdateTask(ctx context.Context, tasks *domain.Task) (*dom
 		&updatedTask.Title,
 		&updatedTask.Description,
 		&updatedTask.Status,
+		&updatedTask.Project,
+		&updatedTask.Assignee,
+		&updatedTask.DueAt,
 		&updatedTask.CreatedAt,
 		&updatedTask.UpdatedAt,
 	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrTaskNotFound
+		}
 		return nil, fmt.Errorf("failed to update task: %w", err)
 	}
 	return &updatedTask, nil
 }
 
 func (r *PostgresRepo) DeleteTask(ctx context.Context, id string) error {
-	_, err := r.pool.Exec(ctx, "DELETE FROM tasks WHERE id = $1", id)
-	return err
+	tag, err := r.pool.Exec(ctx, "DELETE FROM tasks WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrTaskNotFound
+	}
+	return nil
+}
+
+func (r *PostgresRepo) Ping(ctx context.Context) error {
+	return r.pool.Ping(ctx)
 }
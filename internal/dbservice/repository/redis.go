@@ -6,71 +6,251 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/SteepTaq/todo_project/internal/dbservice/domain"
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 )
 
 type RedisRepo struct {
 	client *redis.Client
 	log    *slog.Logger
-	ttl    time.Duration
+	// Меняется на лету через SetPolicy
+	policy atomic.Pointer[CachePolicy]
 }
 
-func NewRedisRepo(addr, password string, db int, ttl time.Duration, logger *slog.Logger) (*RedisRepo, error) {
+type CachePolicy struct {
+	TTL time.Duration
+	// Сколько помнить, что задачи нет, чтобы запросы по неизвестным id не шли в хранилище
+	NegativeTTL time.Duration
+	// Коэффициент XFetch: чем больше, тем раньше до истечения ttl горячая
+	// задача перечитывается из хранилища. 0 - без раннего обновления.
+	EarlyRefreshBeta float64
+}
+
+// cachedTask - запись задачи в Redis. Missing - задачи нет в хранилище.
+type cachedTask struct {
+	Task      *domain.Task  `json:"task,omitempty"`
+	Missing   bool          `json:"missing,omitempty"`
+	Delta     time.Duration `json:"delta,omitempty"`
+	ExpiresAt time.Time     `json:"expires_at"`
+}
+
+// NewRedisRepo не проверяет соединение: Redis - только кеш, и сервис должен
+// стартовать без него. Доступность отслеживает BreakerCache.
+func NewRedisRepo(addr, password string, db int, policy CachePolicy, timeout time.Duration, logger *slog.Logger) (*RedisRepo, error) {
 	client := redis.NewClient(&redis.Options{
-		Addr:     addr,
-		Password: password,
-		DB:       db,
+		Addr:         addr,
+		Password:     password,
+		DB:           db,
+		DialTimeout:  timeout,
+		ReadTimeout:  timeout,
+		WriteTimeout: timeout,
 	})
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := client.Ping(ctx).Err(); err != nil {
-		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	if err := redisotel.InstrumentTracing(client); err != nil {
+		return nil, fmt.Errorf("failed to instrument Redis client: %w", err)
 	}
 
-	return &RedisRepo{
+	r := &RedisRepo{
 		client: client,
 		log:    logger.With("component", "redis_repo"),
-		ttl:    ttl,
-	}, nil
+	}
+	r.policy.Store(&policy)
+	return r, nil
+}
+
+// SetPolicy применяет новые ttl к записям, которые кладутся в кеш после вызова
+func (r *RedisRepo) SetPolicy(policy CachePolicy) {
+	r.policy.Store(&policy)
+}
+
+func (r *RedisRepo) Policy() CachePolicy {
+	return *r.policy.Load()
 }
 
 func (r *RedisRepo) Close() {
 	r.client.Close()
 }
 
-func (r *RedisRepo) SetTask(ctx context.Context, task *domain.Task) error {
-	key := "task:" + task.ID
-	value, err := json.Marshal(task)
+func (r *RedisRepo) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
+// SetTask кладет задачу в кеш. delta - сколько заняло ее чтение из хранилища,
+// по нему решается, насколько заранее обновлять запись.
+func (r *RedisRepo) SetTask(ctx context.Context, task *domain.Task, delta time.Duration) error {
+	return r.set(ctx, task.ID, cachedTask{Task: task, Delta: delta}, r.Policy().TTL)
+}
+
+// SetMissing запоминает, что задачи с таким id нет
+func (r *RedisRepo) SetMissing(ctx context.Context, id string) error {
+	ttl := r.Policy().NegativeTTL
+	if ttl <= 0 {
+		return nil
+	}
+	return r.set(ctx, id, cachedTask{Missing: true}, ttl)
+}
+
+func (r *RedisRepo) set(ctx context.Context, id string, entry cachedTask, ttl time.Duration) error {
+	entry.ExpiresAt = time.Now().Add(ttl)
+	value, err := json.Marshal(entry)
 	if err != nil {
 		return fmt.Errorf("failed to marshal task: %w", err)
 	}
 
-	if err := r.client.Set(ctx, key, value, r.ttl).Err(); err != nil {
+	if err := r.client.Set(ctx, "task:"+id, value, ttl).Err(); err != nil {
 		return fmt.Errorf("failed to set task in Redis: %w", err)
 	}
 
 	return nil
 }
 
+// GetTask возвращает domain.ErrCacheMiss, если задачи нет в кеше или ее пора
+// обновить заранее, и domain.ErrTaskNotFound, если известно, что ее нет в хранилище
 func (r *RedisRepo) GetTask(ctx context.Context, id string) (*domain.Task, error) {
-	key := "task:" + id
-	value, err := r.client.Get(ctx, key).Result()
+	value, err := r.client.Get(ctx, "task:"+id).Bytes()
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
-			return nil, domain.ErrTaskNotFound
+			return nil, domain.ErrCacheMiss
 		}
 		return nil, fmt.Errorf("failed to get task from Redis: %w", err)
 	}
 
-	var task domain.Task
-	if err := json.Unmarshal([]byte(value), &task); err != nil {
+	var entry cachedTask
+	if err := json.Unmarshal(value, &entry); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal task: %w", err)
 	}
+	switch {
+	case entry.Missing:
+		return nil, domain.ErrTaskNotFound
+	case entry.Task == nil:
+		// Запись в старом формате
+		return nil, domain.ErrCacheMiss
+	case r.refreshEarly(entry):
+		return nil, domain.ErrCacheMiss
+	}
+
+	return entry.Task, nil
+}
+
+// refreshEarly - вероятностное раннее обновление (XFetch): чем ближе истечение
+// и чем дольше задача читается из хранилища, тем вероятнее, что этот запрос
+// обновит запись, пока остальные еще получают ее из кеша
+func (r *RedisRepo) refreshEarly(entry cachedTask) bool {
+	beta := r.Policy().EarlyRefreshBeta
+	if beta <= 0 || entry.Delta <= 0 {
+		return false
+	}
+	gap := -float64(entry.Delta) * beta * math.Log(rand.Float64())
+	return time.Now().Add(time.Duration(gap)).After(entry.ExpiresAt)
+}
+
+func (r *RedisRepo) DeleteTask(ctx context.Context, id string) error {
+	if err := r.client.Del(ctx, "task:"+id).Err(); err != nil {
+		return fmt.Errorf("failed to delete task from Redis: %w", err)
+	}
+	return nil
+}
+
+// Списки задач хранятся под ключами с версией. Invalidate увеличивает версию,
+// и старые списки больше не читаются, а потом истекают по ttl.
+const taskListVersionKey = "tasks:list:version"
+
+// ListVersion возвращает текущую версию списков. Ее нужно получить до чтения
+// из хранилища и передать в SetTaskList: если между ними задачи изменились,
+// устаревший список запишется под старой версией и не будет прочитан.
+func (r *RedisRepo) ListVersion(ctx context.Context) (int64, error) {
+	version, err := r.client.Get(ctx, taskListVersionKey).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return 0, fmt.Errorf("failed to get list version from Redis: %w", err)
+	}
+	return version, nil
+}
+
+func (r *RedisRepo) GetTaskList(ctx context.Context, version int64, filter domain.TaskFilter) ([]*domain.Task, error) {
+	value, err := r.client.Get(ctx, taskListKey(version, filter)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, domain.ErrCacheMiss
+		}
+		return nil, fmt.Errorf("failed to get task list from Redis: %w", err)
+	}
+
+	var tasks []*domain.Task
+	if err := json.Unmarshal(value, &tasks); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal task list: %w", err)
+	}
+	return tasks, nil
+}
+
+func (r *RedisRepo) SetTaskList(ctx context.Context, version int64, filter domain.TaskFilter, tasks []*domain.Task) error {
+	value, err := json.Marshal(tasks)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task list: %w", err)
+	}
+	if err := r.client.Set(ctx, taskListKey(version, filter), value, r.Policy().TTL).Err(); err != nil {
+		return fmt.Errorf("failed to set task list in Redis: %w", err)
+	}
+	return nil
+}
+
+// Invalidate делает недействительными все закешированные списки задач
+func (r *RedisRepo) Invalidate(ctx context.Context) error {
+	if err := r.client.Incr(ctx, taskListVersionKey).Err(); err != nil {
+		return fmt.Errorf("failed to invalidate task lists in Redis: %w", err)
+	}
+	return nil
+}
+
+// Flush удаляет все задачи из кеша и делает недействительными списки
+func (r *RedisRepo) Flush(ctx context.Context) error {
+	iter := r.client.Scan(ctx, 0, "task:*", 500).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == 500 {
+			if err := r.client.Unlink(ctx, keys...).Err(); err != nil {
+				return fmt.Errorf("failed to flush tasks from Redis: %w", err)
+			}
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("failed to scan tasks in Redis: %w", err)
+	}
+	if len(keys) > 0 {
+		if err := r.client.Unlink(ctx, keys...).Err(); err != nil {
+			return fmt.Errorf("failed to flush tasks from Redis: %w", err)
+		}
+	}
+	return r.Invalidate(ctx)
+}
+
+const invalidationChannel = "tasks:invalidate"
+
+// PublishInvalidation рассылает сообщение об изменении всем репликам db сервиса
+func (r *RedisRepo) PublishInvalidation(ctx context.Context, msg string) error {
+	return r.client.Publish(ctx, invalidationChannel, msg).Err()
+}
+
+func (r *RedisRepo) SubscribeInvalidations(ctx context.Context) *redis.PubSub {
+	return r.client.Subscribe(ctx, invalidationChannel)
+}
+
+func taskListKey(version int64, filter domain.TaskFilter) string {
+	return fmt.Sprintf("tasks:list:%d:%s", version, filterKey(filter))
+}
 
-	return &task, nil
+// filterKey - одинаковая строка для фильтров, отличающихся только порядком значений
+func filterKey(filter domain.TaskFilter) string {
+	statuses := slices.Sorted(slices.Values(filter.Statuses))
+	ids := slices.Sorted(slices.Values(filter.IDs))
+	return "status=" + strings.Join(statuses, ",") + ";id=" + strings.Join(ids, ",")
 }
@@ -0,0 +1,289 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/SteepTaq/todo_project/internal/dbservice/domain"
+	"github.com/SteepTaq/todo_project/internal/dbservice/migrations"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+)
+
+// Фиксированная ширина и UTC: строки сортируются в том же порядке, что и время.
+// Точность как у timestamptz в Postgres.
+const sqliteTimeLayout = "2006-01-02T15:04:05.000000Z"
+
+// SQLiteRepo хранит задачи во встроенной базе SQLite: db сервис работает
+// одним бинарником без Postgres. Драйвер modernc.org/sqlite без cgo.
+type SQLiteRepo struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+type SQLiteConfig struct {
+	// Путь к файлу базы, ":memory:" - база в памяти процесса
+	Path        string
+	BusyTimeout time.Duration
+}
+
+// NewSQLiteRepo открывает базу, создавая файл при необходимости, и применяет миграции
+func NewSQLiteRepo(cfg SQLiteConfig, logger *slog.Logger) (*SQLiteRepo, error) {
+	memory := cfg.Path == ":memory:"
+	if !memory {
+		if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create database directory: %w", err)
+		}
+	}
+
+	// Прагмы задаются в DSN, чтобы действовать на каждом соединении пула
+	params := url.Values{}
+	params.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", cfg.BusyTimeout.Milliseconds()))
+	params.Add("_pragma", "foreign_keys(1)")
+	if !memory {
+		params.Add("_pragma", "journal_mode(WAL)")
+		params.Add("_pragma", "synchronous(NORMAL)")
+	}
+	db, err := sql.Open("sqlite", "file:"+cfg.Path+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	if memory {
+		// У каждого соединения была бы своя база в памяти
+		db.SetMaxOpenConns(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+	if err := migrateSQLite(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SQLiteRepo{
+		db:  db,
+		log: logger.With("component", "sqlite_repo"),
+	}, nil
+}
+
+func migrateSQLite(db *sql.DB) error {
+	source, err := migrations.SQLite.Source()
+	if err != nil {
+		return err
+	}
+	driver, err := sqlite.WithInstance(db, &sqlite.Config{})
+	if err != nil {
+		return fmt.Errorf("failed to create migration driver: %w", err)
+	}
+	// m.Close закрыл бы и db, поэтому не вызывается
+	m, err := migrate.NewWithInstance("iofs", source, "sqlite", driver)
+	if err != nil {
+		return fmt.Errorf("failed to create migrate instance: %w", err)
+	}
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLiteRepo) Close() {
+	if err := r.db.Close(); err != nil {
+		r.log.Error("failed to close database", "error", err)
+	}
+}
+
+func (r *SQLiteRepo) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+func (r *SQLiteRepo) CountTasksByStatus(ctx context.Context) (map[string]int, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT status, count(*) FROM tasks GROUP BY status")
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tasks: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan task count: %w", err)
+		}
+		counts[status] = count
+	}
+	return counts, rows.Err()
+}
+
+func (r *SQLiteRepo) GetTaskByID(ctx context.Context, id string) (*domain.Task, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT id, title, description, status, project, assignee, due_at, created_at, updated_at
+              FROM tasks WHERE id = ?`, id)
+	task, err := scanSQLiteTask(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+	return task, nil
+}
+
+func (r *SQLiteRepo) GetAllTasks(ctx context.Context) ([]*domain.Task, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, title, description, status, project, assignee, due_at, created_at, updated_at
+              FROM tasks ORDER BY created_at, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tasks: %w", err)
+	}
+	return collectSQLiteTasks(rows, 0)
+}
+
+// ListTasksPage возвращает до limit задач после курсора в порядке (created_at, id).
+// nil курсор - с начала списка.
+func (r *SQLiteRepo) ListTasksPage(ctx context.Context, after *domain.TaskCursor, limit int) ([]*domain.Task, error) {
+	query := `SELECT id, title, description, status, project, assignee, due_at, created_at, updated_at
+              FROM tasks ORDER BY created_at, id LIMIT ?`
+	args := []any{limit}
+	if after != nil {
+		query = `SELECT id, title, description, status, project, assignee, due_at, created_at, updated_at
+                 FROM tasks WHERE (created_at, id) > (?, ?)
+                 ORDER BY created_at, id LIMIT ?`
+		args = []any{formatSQLiteTime(after.CreatedAt), after.ID, limit}
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	return collectSQLiteTasks(rows, limit)
+}
+
+func (r *SQLiteRepo) CreateTask(ctx context.Context, task *domain.Task) (*domain.Task, error) {
+	row := r.db.QueryRowContext(ctx, `INSERT INTO tasks (id, title, description, status, project, assignee, due_at, created_at, updated_at)
+              VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+              RETURNING id, title, description, status, project, assignee, due_at, created_at, updated_at`,
+		task.ID,
+		task.Title,
+		task.Description,
+		task.Status,
+		task.Project,
+		task.Assignee,
+		formatSQLiteDue(task.DueAt),
+		formatSQLiteTime(task.CreatedAt),
+		formatSQLiteTime(task.UpdatedAt),
+	)
+	createdTask, err := scanSQLiteTask(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create task: %w", err)
+	}
+	return createdTask, nil
+}
+
+func (r *SQLiteRepo) UpdateTask(ctx context.Context, task *domain.Task) (*domain.Task, error) {
+	row := r.db.QueryRowContext(ctx, `UPDATE tasks SET title = ?, description = ?, status = ?, project = ?, assignee = ?, due_at = ?, updated_at = ?
+              WHERE id = ? RETURNING id, title, description, status, project, assignee, due_at, created_at, updated_at`,
+		task.Title,
+		task.Description,
+		task.Status,
+		task.Project,
+		task.Assignee,
+		formatSQLiteDue(task.DueAt),
+		formatSQLiteTime(task.UpdatedAt),
+		task.ID,
+	)
+	updatedTask, err := scanSQLiteTask(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("failed to update task: %w", err)
+	}
+	return updatedTask, nil
+}
+
+func (r *SQLiteRepo) DeleteTask(ctx context.Context, id string) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM tasks WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+	if n == 0 {
+		return domain.ErrTaskNotFound
+	}
+	return nil
+}
+
+type sqliteScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSQLiteTask(row sqliteScanner) (*domain.Task, error) {
+	var task domain.Task
+	var description, dueAt, updatedAt sql.NullString
+	var createdAt string
+	if err := row.Scan(&task.ID, &task.Title, &description, &task.Status, &task.Project, &task.Assignee, &dueAt, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	task.Description = description.String
+
+	var err error
+	if task.CreatedAt, err = time.Parse(sqliteTimeLayout, createdAt); err != nil {
+		return nil, fmt.Errorf("invalid created_at %q: %w", createdAt, err)
+	}
+	if updatedAt.Valid {
+		if task.UpdatedAt, err = time.Parse(sqliteTimeLayout, updatedAt.String); err != nil {
+			return nil, fmt.Errorf("invalid updated_at %q: %w", updatedAt.String, err)
+		}
+	}
+	if dueAt.Valid {
+		due, err := time.Parse(sqliteTimeLayout, dueAt.String)
+		if err != nil {
+			return nil, fmt.Errorf("invalid due_at %q: %w", dueAt.String, err)
+		}
+		task.DueAt = &due
+	}
+	return &task, nil
+}
+
+func collectSQLiteTasks(rows *sql.Rows, capacity int) ([]*domain.Task, error) {
+	defer rows.Close()
+
+	tasks := make([]*domain.Task, 0, capacity)
+	for rows.Next() {
+		task, err := scanSQLiteTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// formatSQLiteTime округляет до микросекунд, как Postgres
+func formatSQLiteTime(t time.Time) string {
+	return t.UTC().Round(time.Microsecond).Format(sqliteTimeLayout)
+}
+
+// formatSQLiteDue - срок задачи, nil хранится как NULL
+func formatSQLiteDue(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return formatSQLiteTime(*t)
+}
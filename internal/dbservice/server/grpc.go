@@ -3,10 +3,12 @@ package server
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/SteepTaq/todo_project/internal/dbservice/domain"
 	"github.com/SteepTaq/todo_project/internal/dbservice/service"
 	todov1 "github.com/SteepTaq/todo_project/pkg/proto/gen/todo"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -25,18 +27,24 @@ func (s *GRPCServer) CreateTask(ctx context.Context, req *todov1.CreateTaskReque
 	domainTask := &domain.Task{
 		Title:       req.Task.GetTitle(),
 		Description: req.Task.GetDescription(),
+		Project:     req.Task.GetProject(),
+		Assignee:    req.Task.GetAssignee(),
+		DueAt:       dueFromProto(req.Task.GetDueAt()),
 		Status:      req.Task.GetStatus().String(),
 	}
 
 	newTask, err := s.service.CreateTask(ctx, domainTask)
 	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, taskError(err)
 	}
 
 	pbTask := &todov1.Task{
 		TaskId:      newTask.ID,
 		Title:       newTask.Title,
 		Description: newTask.Description,
+		Project:     newTask.Project,
+		Assignee:    newTask.Assignee,
+		DueAt:       dueToProto(newTask.DueAt),
 		Status:      todov1.TaskStatus(todov1.TaskStatus_value[newTask.Status]),
 		CreatedAt:   timestamppb.New(newTask.CreatedAt),
 		UpdatedAt:   timestamppb.New(newTask.UpdatedAt),
@@ -48,19 +56,32 @@ func (s *GRPCServer) CreateTask(ctx context.Context, req *todov1.CreateTaskReque
 	}, nil
 }
 
+// taskError переводит ошибки сервиса в коды gRPC. Ошибки запроса не должны
+// приходить как Internal: клиент API считает Internal отказом db сервиса.
+func taskError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrTaskNotFound):
+		return status.Error(codes.NotFound, "task not found")
+	case errors.Is(err, domain.ErrInvalidInput):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
 func (s *GRPCServer) GetTask(ctx context.Context, req *todov1.GetTaskRequest) (*todov1.GetTaskResponse, error) {
 	newTask, err := s.service.GetTask(ctx, req.GetId())
 	if err != nil {
-		if errors.Is(err, domain.ErrTaskNotFound) {
-			return nil, status.Error(codes.NotFound, "task not found")
-		}
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, taskError(err)
 	}
 
 	pbTask := &todov1.Task{
 		TaskId:      newTask.ID,
 		Title:       newTask.Title,
 		Description: newTask.Description,
+		Project:     newTask.Project,
+		Assignee:    newTask.Assignee,
+		DueAt:       dueToProto(newTask.DueAt),
 		Status:      todov1.TaskStatus(todov1.TaskStatus_value[newTask.Status]),
 		CreatedAt:   timestamppb.New(newTask.CreatedAt),
 		UpdatedAt:   timestamppb.New(newTask.UpdatedAt),
@@ -71,7 +92,7 @@ func (s *GRPCServer) GetTask(ctx context.Context, req *todov1.GetTaskRequest) (*
 	}, nil
 }
 func (s *GRPCServer) GetAllTasks(ctx context.Context, req *todov1.GetAllTasksRequest) (*todov1.GetAllTasksResponse, error) {
-	newTasks, err := s.service.GetAllTasks(ctx)
+	newTasks, err := s.service.GetAllTasks(ctx, filterFromProto(req.GetStatuses(), req.GetTaskIds()))
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
@@ -81,6 +102,9 @@ func (s *GRPCServer) GetAllTasks(ctx context.Context, req *todov1.GetAllTasksReq
 			TaskId:      task.ID,
 			Title:       task.Title,
 			Description: task.Description,
+			Project:     task.Project,
+			Assignee:    task.Assignee,
+			DueAt:       dueToProto(task.DueAt),
 			Status:      todov1.TaskStatus(todov1.TaskStatus_value[task.Status]),
 			CreatedAt:   timestamppb.New(task.CreatedAt),
 			UpdatedAt:   timestamppb.New(task.UpdatedAt),
@@ -98,6 +122,9 @@ func (s *GRPCServer) UpdateTask(ctx context.Context, req *todov1.UpdateTaskReque
 		ID:          req.Task.GetTaskId(),
 		Title:       req.Task.GetTitle(),
 		Description: req.Task.GetDescription(),
+		Project:     req.Task.GetProject(),
+		Assignee:    req.Task.GetAssignee(),
+		DueAt:       dueFromProto(req.Task.GetDueAt()),
 	}
 	switch req.Task.GetStatus() {
 	case todov1.TaskStatus_TASK_STATUS_PENDING:
@@ -109,13 +136,16 @@ func (s *GRPCServer) UpdateTask(ctx context.Context, req *todov1.UpdateTaskReque
 	}
 	newTask, err := s.service.UpdateTask(ctx, domainTask)
 	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, taskError(err)
 	}
 
 	pbTask := &todov1.Task{
 		TaskId:      newTask.ID,
 		Title:       newTask.Title,
 		Description: newTask.Description,
+		Project:     newTask.Project,
+		Assignee:    newTask.Assignee,
+		DueAt:       dueToProto(newTask.DueAt),
 		CreatedAt:   timestamppb.New(newTask.CreatedAt),
 		UpdatedAt:   timestamppb.New(newTask.UpdatedAt),
 	}
@@ -131,3 +161,145 @@ func (s *GRPCServer) UpdateTask(ctx context.Context, req *todov1.UpdateTaskReque
 		Task: pbTask,
 	}, nil
 }
+
+func (s *GRPCServer) DeleteTask(ctx context.Context, req *todov1.DeleteTaskRequest) (*todov1.DeleteTaskResponse, error) {
+	if err := s.service.DeleteTask(ctx, req.GetTaskId()); err != nil {
+		return nil, taskError(err)
+	}
+
+	return &todov1.DeleteTaskResponse{
+		Success: true,
+		TaskId:  req.GetTaskId(),
+	}, nil
+}
+
+func (s *GRPCServer) WatchTasks(req *todov1.WatchTasksRequest, stream grpc.ServerStreamingServer[todov1.TaskEvent]) error {
+	filter := filterFromProto(req.GetStatuses(), req.GetTaskIds())
+	err := s.service.Watch(stream.Context(), filter, func(c domain.TaskChange) error {
+		return stream.Send(&todov1.TaskEvent{
+			Type:       eventTypeToProto(c.Type),
+			Task:       toProtoTask(&c.Task),
+			OccurredAt: timestamppb.New(c.OccurredAt),
+		})
+	})
+	switch {
+	case errors.Is(err, domain.ErrWatchTooSlow):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	case err != nil:
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+const (
+	defaultStreamPageSize = 500
+	maxStreamPageSize     = 5000
+)
+
+func (s *GRPCServer) StreamTasks(req *todov1.StreamTasksRequest, stream grpc.ServerStreamingServer[todov1.StreamTasksResponse]) error {
+	pageSize := int(req.GetPageSize())
+	switch {
+	case pageSize <= 0:
+		pageSize = defaultStreamPageSize
+	case pageSize > maxStreamPageSize:
+		pageSize = maxStreamPageSize
+	}
+
+	err := s.service.StreamTasks(stream.Context(), pageSize, func(tasks []*domain.Task) error {
+		pbTasks := make([]*todov1.Task, 0, len(tasks))
+		for _, task := range tasks {
+			pbTasks = append(pbTasks, toProtoTask(task))
+		}
+		return stream.Send(&todov1.StreamTasksResponse{Tasks: pbTasks})
+	})
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return status.FromContextError(err).Err()
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+func toProtoTask(task *domain.Task) *todov1.Task {
+	pbTask := &todov1.Task{
+		TaskId:      task.ID,
+		Title:       task.Title,
+		Description: task.Description,
+		Status:      statusToProto(task.Status),
+		Project:     task.Project,
+		Assignee:    task.Assignee,
+		DueAt:       dueToProto(task.DueAt),
+	}
+	if !task.CreatedAt.IsZero() {
+		pbTask.CreatedAt = timestamppb.New(task.CreatedAt)
+	}
+	if !task.UpdatedAt.IsZero() {
+		pbTask.UpdatedAt = timestamppb.New(task.UpdatedAt)
+	}
+	return pbTask
+}
+
+// dueToProto и dueFromProto переводят срок задачи, nil - без срока
+func dueToProto(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func dueFromProto(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+func statusToProto(status string) todov1.TaskStatus {
+	switch status {
+	case "in_progress":
+		return todov1.TaskStatus_TASK_STATUS_IN_PROGRESS
+	case "completed":
+		return todov1.TaskStatus_TASK_STATUS_COMPLETED
+	default:
+		return todov1.TaskStatus_TASK_STATUS_PENDING
+	}
+}
+
+func statusFromProto(status todov1.TaskStatus) string {
+	switch status {
+	case todov1.TaskStatus_TASK_STATUS_IN_PROGRESS:
+		return "in_progress"
+	case todov1.TaskStatus_TASK_STATUS_COMPLETED:
+		return "completed"
+	default:
+		return "pending"
+	}
+}
+
+func filterFromProto(statuses []todov1.TaskStatus, ids []string) domain.TaskFilter {
+	filter := domain.TaskFilter{IDs: ids}
+	for _, st := range statuses {
+		filter.Statuses = append(filter.Statuses, statusFromProto(st))
+	}
+	return filter
+}
+
+func eventTypeToProto(t domain.ChangeType) todov1.TaskEventType {
+	switch t {
+	case domain.TaskCreated:
+		return todov1.TaskEventType_TASK_EVENT_TYPE_CREATED
+	case domain.TaskUpdated:
+		return todov1.TaskEventType_TASK_EVENT_TYPE_UPDATED
+	case domain.TaskDeleted:
+		return todov1.TaskEventType_TASK_EVENT_TYPE_DELETED
+	default:
+		return todov1.TaskEventType_TASK_EVENT_TYPE_UNSPECIFIED
+	}
+}
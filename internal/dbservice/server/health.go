@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// HealthCheck - проверка одной зависимости. Если Critical, ее отказ
+// переводит весь сервис ("") в NOT_SERVING, иначе меняется только статус Name.
+type HealthCheck struct {
+	Name     string
+	Critical bool
+	Ping     func(ctx context.Context) error
+}
+
+// RunHealthChecks периодически выполняет проверки и обновляет статусы grpc.health.v1,
+// пока не завершится контекст. services - имена сервисов, статус которых совпадает с общим.
+func RunHealthChecks(ctx context.Context, hs *health.Server, interval, timeout time.Duration, checks []HealthCheck, services []string, log *slog.Logger) {
+	log = log.With("component", "health")
+	last := make(map[string]healthpb.HealthCheckResponse_ServingStatus)
+
+	check := func() {
+		overall := healthpb.HealthCheckResponse_SERVING
+		for _, c := range checks {
+			pingCtx, cancel := context.WithTimeout(ctx, timeout)
+			err := c.Ping(pingCtx)
+			cancel()
+
+			st := healthpb.HealthCheckResponse_SERVING
+			if err != nil {
+				st = healthpb.HealthCheckResponse_NOT_SERVING
+				if c.Critical {
+					overall = st
+				}
+			}
+			if prev, ok := last[c.Name]; !ok || prev != st {
+				if err != nil {
+					log.Warn("dependency is unhealthy", "check", c.Name, "error", err)
+				} else if ok {
+					log.Info("dependency recovered", "check", c.Name)
+				}
+				last[c.Name] = st
+			}
+			hs.SetServingStatus(c.Name, st)
+		}
+
+		hs.SetServingStatus("", overall)
+		for _, name := range services {
+			hs.SetServingStatus(name, overall)
+		}
+	}
+
+	check()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			check()
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package server
+
+import (
+	"container/list"
+	"context"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	todov1 "github.com/SteepTaq/todo_project/pkg/proto/gen/todo"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	concurrencyLimit = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "grpc_server_concurrency_limit",
+		Help: "Current limit of concurrent TodoService calls.",
+	})
+	inflightRequests = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "grpc_server_inflight_requests",
+		Help: "TodoService calls being handled now.",
+	})
+	shedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_shed_requests_total",
+		Help: "TodoService calls rejected with ResourceExhausted by method.",
+	}, []string{"method"})
+)
+
+type ConcurrencyConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Начальный лимит одновременных вызовов; без Adaptive он не меняется
+	Limit    int  `mapstructure:"limit"`
+	Adaptive bool `mapstructure:"adaptive"`
+	MinLimit int  `mapstructure:"min_limit"`
+	MaxLimit int  `mapstructure:"max_limit"`
+	// Пока вызовы укладываются в TargetLatency, лимит растет, иначе уменьшается
+	TargetLatency time.Duration `mapstructure:"target_latency"`
+	// Доля лимита, которую могут занять записи и выгрузки: остаток всегда доступен чтениям
+	WriteShare float64 `mapstructure:"write_share"`
+	// Сколько вызовов ждут освобождения места и сколько времени
+	QueueSize    int           `mapstructure:"queue_size"`
+	QueueTimeout time.Duration `mapstructure:"queue_timeout"`
+	// Лимиты по именам методов (GetTask, StreamTasks, ...)
+	MethodLimits map[string]int `mapstructure:"method_limits"`
+}
+
+// Приоритеты вызовов: из очереди первыми выходят чтения, последними - выгрузки
+type priority int
+
+const (
+	priorityRead priority = iota
+	priorityWrite
+	priorityBulk
+	priorityCount
+)
+
+// WatchTasks живет, пока клиент подписан, поэтому не ограничивается
+var priorities = map[string]priority{
+	todov1.TodoService_GetTask_FullMethodName:     priorityRead,
+	todov1.TodoService_GetAllTasks_FullMethodName: priorityRead,
+	todov1.TodoService_CreateTask_FullMethodName:  priorityWrite,
+	todov1.TodoService_UpdateTask_FullMethodName:  priorityWrite,
+	todov1.TodoService_DeleteTask_FullMethodName:  priorityWrite,
+	todov1.TodoService_StreamTasks_FullMethodName: priorityBulk,
+}
+
+var errOverloaded = status.Error(codes.ResourceExhausted, "server is overloaded, retry later")
+
+// ConcurrencyLimiter ограничивает число одновременных вызовов TodoService.
+// Сверх лимита вызов недолго ждет в очереди, затем получает ResourceExhausted.
+type ConcurrencyLimiter struct {
+	cfg ConcurrencyConfig
+
+	mu       sync.Mutex
+	limit    float64
+	inflight int
+	byMethod map[string]int
+	queued   int
+	queues   [priorityCount]*list.List
+	// Когда лимит последний раз уменьшался: не чаще раза за TargetLatency
+	decreased time.Time
+}
+
+type waiter struct {
+	method string
+	prio   priority
+	ready  chan struct{}
+}
+
+func NewConcurrencyLimiter(cfg ConcurrencyConfig) *ConcurrencyLimiter {
+	if cfg.MinLimit <= 0 {
+		cfg.MinLimit = 1
+	}
+	if cfg.MaxLimit < cfg.Limit {
+		cfg.MaxLimit = cfg.Limit
+	}
+	if cfg.WriteShare <= 0 || cfg.WriteShare > 1 {
+		cfg.WriteShare = 1
+	}
+	// viper приводит ключи к нижнему регистру
+	methodLimits := make(map[string]int, len(cfg.MethodLimits))
+	for name, n := range cfg.MethodLimits {
+		methodLimits[strings.ToLower(name)] = n
+	}
+	cfg.MethodLimits = methodLimits
+	l := &ConcurrencyLimiter{
+		cfg:      cfg,
+		limit:    float64(max(cfg.Limit, cfg.MinLimit)),
+		byMethod: make(map[string]int),
+	}
+	for i := range l.queues {
+		l.queues[i] = list.New()
+	}
+	concurrencyLimit.Set(l.limit)
+	return l
+}
+
+func (l *ConcurrencyLimiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		prio, ok := priorities[info.FullMethod]
+		if !l.cfg.Enabled || !ok {
+			return handler(ctx, req)
+		}
+		if err := l.acquire(ctx, info.FullMethod, prio); err != nil {
+			return nil, err
+		}
+		start := time.Now()
+		code := codes.Internal
+		defer func() { l.release(info.FullMethod, time.Since(start), code) }()
+		resp, err := handler(ctx, req)
+		code = status.Code(err)
+		return resp, err
+	}
+}
+
+func (l *ConcurrencyLimiter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		prio, ok := priorities[info.FullMethod]
+		if !l.cfg.Enabled || !ok {
+			return handler(srv, ss)
+		}
+		if err := l.acquire(ss.Context(), info.FullMethod, prio); err != nil {
+			return err
+		}
+		// Длительность потока зависит от объема выгрузки, а не от нагрузки,
+		// поэтому на лимит она не влияет
+		defer l.release(info.FullMethod, 0, codes.OK)
+		return handler(srv, ss)
+	}
+}
+
+// allowed - можно ли сейчас начать вызов. Вызывается под mu.
+func (l *ConcurrencyLimiter) allowed(method string, prio priority) bool {
+	limit := l.limit
+	if prio != priorityRead {
+		limit *= l.cfg.WriteShare
+	}
+	return float64(l.inflight) < max(limit, 1) && l.methodAllowed(method)
+}
+
+func (l *ConcurrencyLimiter) methodAllowed(method string) bool {
+	n, ok := l.cfg.MethodLimits[strings.ToLower(path.Base(method))]
+	return !ok || l.byMethod[method] < n
+}
+
+// waiting - ждут ли в очереди вызовы не ниже приоритетом, которым не мешает
+// лимит их метода. Вызывается под mu.
+func (l *ConcurrencyLimiter) waiting(prio priority) bool {
+	for p := priorityRead; p <= prio; p++ {
+		for e := l.queues[p].Front(); e != nil; e = e.Next() {
+			if l.methodAllowed(e.Value.(*waiter).method) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (l *ConcurrencyLimiter) start(method string) {
+	l.inflight++
+	l.byMethod[method]++
+	inflightRequests.Set(float64(l.inflight))
+}
+
+func (l *ConcurrencyLimiter) acquire(ctx context.Context, method string, prio priority) error {
+	l.mu.Lock()
+	if !l.waiting(prio) && l.allowed(method, prio) {
+		l.start(method)
+		l.mu.Unlock()
+		return nil
+	}
+	if l.queued >= l.cfg.QueueSize || l.cfg.QueueTimeout <= 0 {
+		l.mu.Unlock()
+		shedRequests.WithLabelValues(path.Base(method)).Inc()
+		return errOverloaded
+	}
+	w := &waiter{method: method, prio: prio, ready: make(chan struct{})}
+	elem := l.queues[prio].PushBack(w)
+	l.queued++
+	l.mu.Unlock()
+
+	timer := time.NewTimer(l.cfg.QueueTimeout)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case <-w.ready:
+		return nil
+	case <-timer.C:
+		err = errOverloaded
+	case <-ctx.Done():
+		err = status.FromContextError(ctx.Err()).Err()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	select {
+	case <-w.ready:
+		// Место освободилось одновременно с таймаутом
+		return nil
+	default:
+	}
+	l.queues[prio].Remove(elem)
+	l.queued--
+	if status.Code(err) == codes.ResourceExhausted {
+		shedRequests.WithLabelValues(path.Base(method)).Inc()
+	}
+	return err
+}
+
+func (l *ConcurrencyLimiter) release(method string, latency time.Duration, code codes.Code) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inflight--
+	l.byMethod[method]--
+	inflightRequests.Set(float64(l.inflight))
+	if latency > 0 {
+		l.adapt(latency, code)
+	}
+
+	// Будим ожидающих, начиная с самого высокого приоритета
+	for _, queue := range l.queues {
+		for e := queue.Front(); e != nil; {
+			next := e.Next()
+			w := e.Value.(*waiter)
+			if l.allowed(w.method, w.prio) {
+				queue.Remove(e)
+				l.queued--
+				l.start(w.method)
+				close(w.ready)
+			}
+			e = next
+		}
+	}
+}
+
+// adapt меняет лимит по задержке вызова (AIMD): медленный вызов уменьшает лимит
+// на 10%, быстрый при занятом лимите увеличивает его примерно на 1 за каждые limit вызовов.
+// Вызывается под mu.
+func (l *ConcurrencyLimiter) adapt(latency time.Duration, code codes.Code) {
+	if !l.cfg.Adaptive || l.cfg.TargetLatency <= 0 {
+		return
+	}
+	now := time.Now()
+	switch {
+	case latency > l.cfg.TargetLatency || code == codes.DeadlineExceeded:
+		if now.Sub(l.decreased) < l.cfg.TargetLatency {
+			return
+		}
+		l.decreased = now
+		l.limit = max(l.limit*0.9, float64(l.cfg.MinLimit))
+	case float64(l.inflight+1) >= l.limit/2:
+		l.limit = min(l.limit+1/l.limit, float64(l.cfg.MaxLimit))
+	default:
+		return
+	}
+	concurrencyLimit.Set(l.limit)
+}
+
+// Limit возвращает текущий лимит одновременных вызовов
+func (l *ConcurrencyLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
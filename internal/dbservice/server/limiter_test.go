@@ -0,0 +1,115 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	todov1 "github.com/SteepTaq/todo_project/pkg/proto/gen/todo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	getTask    = &grpc.UnaryServerInfo{FullMethod: todov1.TodoService_GetTask_FullMethodName}
+	createTask = &grpc.UnaryServerInfo{FullMethod: todov1.TodoService_CreateTask_FullMethodName}
+)
+
+// call запускает вызов, который держит место, пока не закроют release
+func call(interceptor grpc.UnaryServerInterceptor, info *grpc.UnaryServerInfo, started chan<- string, release <-chan struct{}) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+			started <- info.FullMethod
+			<-release
+			return nil, nil
+		})
+		done <- err
+	}()
+	return done
+}
+
+func TestConcurrencyLimiterShedsWritesFirst(t *testing.T) {
+	l := NewConcurrencyLimiter(ConcurrencyConfig{
+		Enabled:      true,
+		Limit:        2,
+		WriteShare:   0.5,
+		QueueSize:    10,
+		QueueTimeout: 50 * time.Millisecond,
+	})
+	interceptor := l.UnaryServerInterceptor()
+	started := make(chan string, 10)
+	release := make(chan struct{})
+
+	first := call(interceptor, getTask, started, release)
+	<-started
+
+	// Запись не помещается в свою долю лимита и сбрасывается, а чтение проходит
+	err := <-call(interceptor, createTask, started, release)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	second := call(interceptor, getTask, started, release)
+	<-started
+
+	close(release)
+	require.NoError(t, <-first)
+	require.NoError(t, <-second)
+}
+
+func TestConcurrencyLimiterQueuesReadsAheadOfWrites(t *testing.T) {
+	l := NewConcurrencyLimiter(ConcurrencyConfig{
+		Enabled:      true,
+		Limit:        1,
+		QueueSize:    10,
+		QueueTimeout: time.Second,
+	})
+	interceptor := l.UnaryServerInterceptor()
+	started := make(chan string, 10)
+	hold := make(chan struct{})
+
+	busy := call(interceptor, getTask, started, hold)
+	<-started
+
+	release := make(chan struct{})
+	close(release)
+	write := call(interceptor, createTask, started, release)
+	assert.Eventually(t, func() bool { l.mu.Lock(); defer l.mu.Unlock(); return l.queued == 1 }, time.Second, time.Millisecond)
+	read := call(interceptor, getTask, started, release)
+	assert.Eventually(t, func() bool { l.mu.Lock(); defer l.mu.Unlock(); return l.queued == 2 }, time.Second, time.Millisecond)
+
+	close(hold)
+	require.NoError(t, <-busy)
+	assert.Equal(t, todov1.TodoService_GetTask_FullMethodName, <-started)
+	assert.Equal(t, todov1.TodoService_CreateTask_FullMethodName, <-started)
+	require.NoError(t, <-read)
+	require.NoError(t, <-write)
+}
+
+func TestConcurrencyLimiterAdapts(t *testing.T) {
+	l := NewConcurrencyLimiter(ConcurrencyConfig{
+		Enabled:       true,
+		Limit:         10,
+		Adaptive:      true,
+		MinLimit:      2,
+		MaxLimit:      20,
+		TargetLatency: time.Millisecond,
+	})
+
+	l.mu.Lock()
+	l.inflight = 10
+	l.adapt(10*time.Millisecond, codes.OK)
+	assert.InDelta(t, 9, l.limit, 0.01)
+	// Повторное замедление сразу после уменьшения лимит не трогает
+	l.adapt(10*time.Millisecond, codes.OK)
+	assert.InDelta(t, 9, l.limit, 0.01)
+
+	for range 100 {
+		l.adapt(time.Microsecond, codes.OK)
+	}
+	assert.Greater(t, l.limit, 9.0)
+	assert.LessOrEqual(t, l.limit, 20.0)
+	l.mu.Unlock()
+}
@@ -0,0 +1,59 @@
+package service
+
+import "sync"
+
+// fills следит, не изменилась ли задача, пока ее загрузка из хранилища
+// была в полете. Счетчик изменений хранится только для задач, которые
+// сейчас загружаются.
+type fills struct {
+	mu    sync.Mutex
+	loads map[string]*pendingFill
+}
+
+type pendingFill struct {
+	refs   int
+	writes uint64
+}
+
+func newFills() *fills {
+	return &fills{loads: make(map[string]*pendingFill)}
+}
+
+// begin вызывается до чтения из хранилища и возвращает номер изменения для done
+func (f *fills) begin(id string) uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p := f.loads[id]
+	if p == nil {
+		p = &pendingFill{}
+		f.loads[id] = p
+	}
+	p.refs++
+	return p.writes
+}
+
+// written отмечает изменение задачи. Вызывается после записи в хранилище
+// и до удаления задачи из кеша.
+func (f *fills) written(id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if p := f.loads[id]; p != nil {
+		p.writes++
+	}
+}
+
+// done вызывается после записи в кеш. false - задачу изменили во время
+// загрузки, и записанное в кеш значение могло устареть.
+func (f *fills) done(id string, writes uint64) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p := f.loads[id]
+	fresh := p.writes == writes
+	if p.refs--; p.refs == 0 {
+		delete(f.loads, id)
+	}
+	return fresh
+}
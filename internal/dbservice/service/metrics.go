@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var cacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "task_cache_requests_total",
+	Help: "Cache lookups in TaskService.GetTask by result: hit, negative_hit, miss, error or bypass when the cache is unavailable.",
+}, []string{"result"})
+
+// StatusCollector отдает количество задач по статусам, опрашивая хранилище при каждом сборе метрик
+type StatusCollector struct {
+	storage TaskRepository
+	timeout time.Duration
+	desc    *prometheus.Desc
+	errors  prometheus.Counter
+}
+
+func NewStatusCollector(storage TaskRepository, timeout time.Duration) *StatusCollector {
+	return &StatusCollector{
+		storage: storage,
+		timeout: timeout,
+		desc:    prometheus.NewDesc("tasks", "Number of tasks by status.", []string{"status"}, nil),
+		errors: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "tasks_count_errors_total",
+			Help: "Failed attempts to count tasks by status.",
+		}),
+	}
+}
+
+func (c *StatusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+	c.errors.Describe(ch)
+}
+
+func (c *StatusCollector) Collect(ch chan<- prometheus.Metric) {
+	defer c.errors.Collect(ch)
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	counts, err := c.storage.CountTasksByStatus(ctx)
+	if err != nil {
+		c.errors.Inc()
+		return
+	}
+	for _, status := range []string{"pending", "in_progress", "completed"} {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(counts[status]), status)
+	}
+}
@@ -3,44 +3,94 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"time"
+	"unicode/utf8"
 
 	"github.com/SteepTaq/todo_project/internal/dbservice/domain"
+	ctxLog "github.com/SteepTaq/todo_project/pkg/context"
 	"github.com/google/uuid"
+	"golang.org/x/sync/singleflight"
 )
 
 type TaskService struct {
-	storage TaskRepository
-	cache   TaskCache
-	log     *slog.Logger
+	storage  TaskRepository
+	cache    TaskCache
+	log      *slog.Logger
+	watchers *watchers
+	loads    singleflight.Group
+	fills    *fills
 }
 
 type TaskRepository interface {
 	CreateTask(ctx context.Context, task *domain.Task) (*domain.Task, error)
 	GetTaskByID(ctx context.Context, id string) (*domain.Task, error)
 	GetAllTasks(ctx context.Context) ([]*domain.Task, error)
+	ListTasksPage(ctx context.Context, after *domain.TaskCursor, limit int) ([]*domain.Task, error)
 	UpdateTask(ctx context.Context, tasks *domain.Task) (*domain.Task, error)
+	DeleteTask(ctx context.Context, id string) error
+	CountTasksByStatus(ctx context.Context) (map[string]int, error)
 }
 
 type TaskCache interface {
-	SetTask(ctx context.Context, task *domain.Task) error
+	// delta - сколько заняло получение задачи, от него зависит раннее обновление записи
+	SetTask(ctx context.Context, task *domain.Task, delta time.Duration) error
+	// SetMissing запоминает, что задачи нет, и GetTask вернет domain.ErrTaskNotFound.
+	// Промах кеша - domain.ErrCacheMiss.
+	SetMissing(ctx context.Context, id string) error
 	GetTask(ctx context.Context, id string) (*domain.Task, error)
+	DeleteTask(ctx context.Context, id string) error
+	// Списки кешируются под версией: ListVersion читается до обращения к хранилищу,
+	// а Invalidate после любого изменения задач делает старые списки недействительными
+	ListVersion(ctx context.Context) (int64, error)
+	GetTaskList(ctx context.Context, version int64, filter domain.TaskFilter) ([]*domain.Task, error)
+	SetTaskList(ctx context.Context, version int64, filter domain.TaskFilter, tasks []*domain.Task) error
+	Invalidate(ctx context.Context) error
 }
 
 func NewTaskService(storage TaskRepository, cache TaskCache, logger *slog.Logger) *TaskService {
 	return &TaskService{
-		storage: storage,
-		cache:   cache,
-		log:     logger.With("component", "task_service"),
+		storage:  storage,
+		cache:    cache,
+		log:      logger.With("component", "task_service"),
+		watchers: newWatchers(),
+		fills:    newFills(),
 	}
 }
 
+// logger возвращает логгер запроса из контекста, чтобы логи сервиса
+// можно было сопоставить с логами API по request_id
+func (s *TaskService) logger(ctx context.Context) *slog.Logger {
+	if l := ctxLog.LoggerFromContextOr(ctx, nil); l != nil {
+		return l.With("component", "task_service")
+	}
+	return s.log
+}
+
+// validateTask проверяет ограничения таблицы tasks, чтобы слишком длинные
+// поля были ошибкой запроса, а не ошибкой хранилища
+func validateTask(task *domain.Task) error {
+	switch {
+	case task.Title == "":
+		return domain.ErrInvalidInput
+	case utf8.RuneCountInString(task.Title) > 255:
+		return fmt.Errorf("%w: title is longer than 255 characters", domain.ErrInvalidInput)
+	case utf8.RuneCountInString(task.Description) > 1000:
+		return fmt.Errorf("%w: description is longer than 1000 characters", domain.ErrInvalidInput)
+	case utf8.RuneCountInString(task.Project) > 100:
+		return fmt.Errorf("%w: project is longer than 100 characters", domain.ErrInvalidInput)
+	case utf8.RuneCountInString(task.Assignee) > 255:
+		return fmt.Errorf("%w: assignee is longer than 255 characters", domain.ErrInvalidInput)
+	}
+	return nil
+}
+
 func (s *TaskService) CreateTask(ctx context.Context, task *domain.Task) (*domain.Task, error) {
 	start := time.Now()
 
-	if task.Title == "" {
-		return nil, domain.ErrInvalidInput
+	if err := validateTask(task); err != nil {
+		return nil, err
 	}
 	newID := uuid.New().String()
 
@@ -48,6 +98,9 @@ func (s *TaskService) CreateTask(ctx context.Context, task *domain.Task) (*domai
 		ID:          newID,
 		Title:       task.Title,
 		Description: task.Description,
+		Project:     task.Project,
+		Assignee:    task.Assignee,
+		DueAt:       task.DueAt,
 		Status:      "pending",
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
@@ -55,15 +108,16 @@ func (s *TaskService) CreateTask(ctx context.Context, task *domain.Task) (*domai
 
 	createdTask, err := s.storage.CreateTask(ctx, newTask)
 	if err != nil {
-		s.log.Error("failed to create task", "error", err)
+		s.logger(ctx).Error("failed to create task", "error", err)
 		return nil, err
 	}
 
-	if err := s.cache.SetTask(ctx, createdTask); err != nil {
-		s.log.Warn("failed to cache task", "task_id", createdTask.ID, "error", err)
-	}
+	s.cacheTask(ctx, createdTask, time.Since(start))
+	s.invalidateLists(ctx)
+
+	s.notify(domain.TaskCreated, createdTask)
 
-	s.log.Info("task created",
+	s.logger(ctx).Info("task created",
 		"task_id", createdTask.ID,
 		"duration", time.Since(start))
 
@@ -73,14 +127,17 @@ func (s *TaskService) CreateTask(ctx context.Context, task *domain.Task) (*domai
 func (s *TaskService) UpdateTask(ctx context.Context, task *domain.Task) (*domain.Task, error) {
 	start := time.Now()
 
-	if task.Title == "" {
-		return nil, domain.ErrInvalidInput
+	if err := validateTask(task); err != nil {
+		return nil, err
 	}
 	
 	newTask := &domain.Task{
 		ID:          task.ID,
 		Title:       task.Title,
 		Description: task.Description,
+		Project:     task.Project,
+		Assignee:    task.Assignee,
+		DueAt:       task.DueAt,
 		Status:      task.Status,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
@@ -88,15 +145,18 @@ func (s *TaskService) UpdateTask(ctx context.Context, task *domain.Task) (*domai
 
 	updatedTask, err := s.storage.UpdateTask(ctx, newTask)
 	if err != nil {
-		s.log.Error("failed to create task", "error", err)
+		s.logger(ctx).Error("failed to create task", "error", err)
 		return nil, err
 	}
 
-	if err := s.cache.SetTask(ctx, updatedTask); err != nil {
-		s.log.Warn("failed to cache task", "task_id", updatedTask.ID, "error", err)
-	}
+	s.loads.Forget(updatedTask.ID)
+	s.fills.written(updatedTask.ID)
+	s.evictTask(ctx, updatedTask.ID)
+	s.invalidateLists(ctx)
+
+	s.notify(domain.TaskUpdated, updatedTask)
 
-	s.log.Info("task updated",
+	s.logger(ctx).Info("task updated",
 		"task_id", updatedTask.ID,
 		"duration", time.Since(start))
 
@@ -106,44 +166,197 @@ func (s *TaskService) UpdateTask(ctx context.Context, task *domain.Task) (*domai
 func (s *TaskService) GetTask(ctx context.Context, id string) (*domain.Task, error) {
 	start := time.Now()
 
-	if cachedTask, err := s.cache.GetTask(ctx, id); err == nil {
-		s.log.Debug("task retrieved from cache",
+	cachedTask, err := s.cache.GetTask(ctx, id)
+	switch {
+	case err == nil:
+		cacheRequests.WithLabelValues("hit").Inc()
+		s.logger(ctx).Debug("task retrieved from cache",
 			"task_id", id,
 			"duration", time.Since(start))
 		return cachedTask, nil
+	case errors.Is(err, domain.ErrTaskNotFound):
+		cacheRequests.WithLabelValues("negative_hit").Inc()
+		s.logger(ctx).Warn("task not found", "task_id", id, "cached", true)
+		return nil, err
+	case errors.Is(err, domain.ErrCacheUnavailable):
+		cacheRequests.WithLabelValues("bypass").Inc()
+	case errors.Is(err, domain.ErrCacheMiss):
+		cacheRequests.WithLabelValues("miss").Inc()
+	default:
+		cacheRequests.WithLabelValues("error").Inc()
+		s.logger(ctx).Warn("failed to get task from cache", "task_id", id, "error", err)
+	}
+
+	// Одновременные промахи по одной задаче идут в хранилище одним запросом.
+	// Загрузка не отменяется вместе с первым запросом, ее результат ждут остальные.
+	ch := s.loads.DoChan(id, func() (any, error) {
+		return s.loadTask(context.WithoutCancel(ctx), id)
+	})
+	var res singleflight.Result
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res = <-ch:
+	}
+	if res.Err != nil {
+		if errors.Is(res.Err, domain.ErrTaskNotFound) {
+			s.logger(ctx).Warn("task not found", "task_id", id)
+		} else {
+			s.logger(ctx).Error("failed to get task", "task_id", id, "error", res.Err)
+		}
+		return nil, res.Err
 	}
 
+	s.logger(ctx).Debug("task retrieved from storage",
+		"task_id", id,
+		"shared", res.Shared,
+		"duration", time.Since(start))
+
+	return res.Val.(*domain.Task), nil
+}
+
+// loadTask читает задачу из хранилища и кладет в кеш ее или отметку, что ее нет.
+// Если задачу изменили во время загрузки, записанное значение удаляется из кеша.
+func (s *TaskService) loadTask(ctx context.Context, id string) (*domain.Task, error) {
+	start := time.Now()
+	writes := s.fills.begin(id)
+
 	task, err := s.storage.GetTaskByID(ctx, id)
+	switch {
+	case errors.Is(err, domain.ErrTaskNotFound):
+		if err := s.cache.SetMissing(ctx, id); err != nil && !errors.Is(err, domain.ErrCacheUnavailable) {
+			s.logger(ctx).Warn("failed to cache missing task", "task_id", id, "error", err)
+		}
+	case err == nil:
+		s.cacheTask(ctx, task, time.Since(start))
+	}
+
+	if !s.fills.done(id, writes) {
+		s.evictTask(ctx, id)
+	}
+	return task, err
+}
+
+// GetAllTasks возвращает задачи, подходящие под filter. Каждый фильтр кешируется отдельно.
+func (s *TaskService) GetAllTasks(ctx context.Context, filter domain.TaskFilter) ([]*domain.Task, error) {
+	start := time.Now()
+
+	version, versionErr := s.cache.ListVersion(ctx)
+	if versionErr == nil {
+		if tasks, err := s.cache.GetTaskList(ctx, version, filter); err == nil {
+			s.logger(ctx).Debug("tasks retrieved from cache",
+				"count", len(tasks),
+				"duration", time.Since(start))
+			return tasks, nil
+		}
+	}
+
+	all, err := s.storage.GetAllTasks(ctx)
 	if err != nil {
+		s.logger(ctx).Error("failed to get tasks", "error", err)
+		return nil, err
+	}
+	tasks := make([]*domain.Task, 0, len(all))
+	for _, task := range all {
+		if matchesTask(filter, task) {
+			tasks = append(tasks, task)
+		}
+	}
+
+	if versionErr == nil {
+		if err := s.cache.SetTaskList(ctx, version, filter, tasks); err != nil && !errors.Is(err, domain.ErrCacheUnavailable) {
+			s.logger(ctx).Warn("failed to cache task list", "error", err)
+		}
+	}
+
+	s.logger(ctx).Debug("task retrieved from storage",
+		"duration", time.Since(start))
+
+	return tasks, nil
+}
+
+func (s *TaskService) DeleteTask(ctx context.Context, id string) error {
+	start := time.Now()
+
+	if err := s.storage.DeleteTask(ctx, id); err != nil {
 		if errors.Is(err, domain.ErrTaskNotFound) {
-			s.log.Warn("task not found", "task_id", id)
+			s.logger(ctx).Warn("task not found", "task_id", id)
 		} else {
-			s.log.Error("failed to get task", "task_id", id, "error", err)
+			s.logger(ctx).Error("failed to delete task", "task_id", id, "error", err)
 		}
-		return nil, err
+		return err
 	}
 
-	if err := s.cache.SetTask(ctx, task); err != nil {
-		s.log.Warn("failed to cache task", "task_id", id, "error", err)
-	}
+	s.loads.Forget(id)
+	s.fills.written(id)
+	s.evictTask(ctx, id)
+	s.invalidateLists(ctx)
+
+	s.notify(domain.TaskDeleted, &domain.Task{ID: id})
 
-	s.log.Debug("task retrieved from storage",
+	s.logger(ctx).Info("task deleted",
 		"task_id", id,
 		"duration", time.Since(start))
 
-	return task, nil
+	return nil
 }
-func (s *TaskService) GetAllTasks(ctx context.Context) ([]*domain.Task, error) {
+
+// cacheTask кладет задачу в кеш. Ошибка кеша не ломает запрос, а об
+// отключенном кеше уже сообщил BreakerCache, поэтому ее не логируем.
+func (s *TaskService) cacheTask(ctx context.Context, task *domain.Task, delta time.Duration) {
+	// Изменение уже в хранилище: отмена запроса не должна оставить кеш устаревшим
+	ctx = context.WithoutCancel(ctx)
+	if err := s.cache.SetTask(ctx, task, delta); err != nil && !errors.Is(err, domain.ErrCacheUnavailable) {
+		s.logger(ctx).Warn("failed to cache task", "task_id", task.ID, "error", err)
+	}
+}
+
+// evictTask удаляет задачу из кеша после изменения, новое значение положит
+// следующее чтение. Запись вместо удаления гонялась бы с загрузкой в loadTask.
+func (s *TaskService) evictTask(ctx context.Context, id string) {
+	ctx = context.WithoutCancel(ctx)
+	if err := s.cache.DeleteTask(ctx, id); err != nil && !errors.Is(err, domain.ErrCacheUnavailable) {
+		s.logger(ctx).Warn("failed to delete task from cache", "task_id", id, "error", err)
+	}
+}
+
+func (s *TaskService) invalidateLists(ctx context.Context) {
+	ctx = context.WithoutCancel(ctx)
+	if err := s.cache.Invalidate(ctx); err != nil && !errors.Is(err, domain.ErrCacheUnavailable) {
+		s.logger(ctx).Warn("failed to invalidate cached task lists", "error", err)
+	}
+}
+
+// StreamTasks обходит все задачи порциями по pageSize и передает каждую порцию в fn
+func (s *TaskService) StreamTasks(ctx context.Context, pageSize int, fn func([]*domain.Task) error) error {
 	start := time.Now()
+	total := 0
 
-	tasks, err := s.storage.GetAllTasks(ctx)
-	if err != nil {
-		s.log.Error("failed to get tasks", "error", err)
-		return nil, err
+	var cursor *domain.TaskCursor
+	for {
+		tasks, err := s.storage.ListTasksPage(ctx, cursor, pageSize)
+		if err != nil {
+			s.logger(ctx).Error("failed to list tasks", "error", err, "sent", total)
+			return err
+		}
+		if len(tasks) == 0 {
+			break
+		}
+		if err := fn(tasks); err != nil {
+			return err
+		}
+		total += len(tasks)
+
+		last := tasks[len(tasks)-1]
+		cursor = &domain.TaskCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		if len(tasks) < pageSize {
+			break
+		}
 	}
 
-	s.log.Debug("task retrieved from storage",
+	s.logger(ctx).Debug("tasks streamed from storage",
+		"count", total,
 		"duration", time.Since(start))
 
-	return tasks, nil
+	return nil
 }
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SteepTaq/todo_project/internal/dbservice/domain"
+	"github.com/SteepTaq/todo_project/internal/dbservice/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type slowStorage struct {
+	TaskRepository
+	calls atomic.Int32
+}
+
+func (s *slowStorage) GetTaskByID(ctx context.Context, id string) (*domain.Task, error) {
+	s.calls.Add(1)
+	time.Sleep(50 * time.Millisecond)
+	if id != "1" {
+		return nil, domain.ErrTaskNotFound
+	}
+	return &domain.Task{ID: id, Title: "Task"}, nil
+}
+
+type mapCache struct {
+	TaskCache
+	mu      sync.Mutex
+	tasks   map[string]*domain.Task
+	missing map[string]bool
+}
+
+func (c *mapCache) SetTask(ctx context.Context, task *domain.Task, delta time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tasks[task.ID] = task
+	return nil
+}
+
+func (c *mapCache) SetMissing(ctx context.Context, id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.missing[id] = true
+	return nil
+}
+
+func (c *mapCache) GetTask(ctx context.Context, id string) (*domain.Task, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.missing[id] {
+		return nil, domain.ErrTaskNotFound
+	}
+	if task, ok := c.tasks[id]; ok {
+		return task, nil
+	}
+	return nil, domain.ErrCacheMiss
+}
+
+func (c *mapCache) DeleteTask(ctx context.Context, id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.tasks, id)
+	delete(c.missing, id)
+	return nil
+}
+
+func (c *mapCache) Invalidate(ctx context.Context) error {
+	return nil
+}
+
+func TestGetTaskCoalescing(t *testing.T) {
+	storage := &slowStorage{}
+	cache := &mapCache{tasks: map[string]*domain.Task{}, missing: map[string]bool{}}
+	s := NewTaskService(storage, cache, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			task, err := s.GetTask(ctx, "1")
+			assert.NoError(t, err)
+			assert.Equal(t, "Task", task.Title)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), storage.calls.Load())
+
+	// Неизвестный id запоминается, и повторный запрос не идет в хранилище
+	for range 3 {
+		_, err := s.GetTask(ctx, "unknown")
+		require.ErrorIs(t, err, domain.ErrTaskNotFound)
+	}
+	assert.Equal(t, int32(2), storage.calls.Load())
+}
+
+func TestGetAllTasksCachesFilteredLists(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cache := repository.NewMemoryCache(repository.CachePolicy{TTL: time.Minute, NegativeTTL: time.Minute})
+	s := NewTaskService(repository.NewMemoryRepo(log), cache, log)
+	ctx := context.Background()
+
+	first, err := s.CreateTask(ctx, &domain.Task{Title: "first"})
+	require.NoError(t, err)
+	second, err := s.CreateTask(ctx, &domain.Task{Title: "second"})
+	require.NoError(t, err)
+
+	_, err = s.UpdateTask(ctx, &domain.Task{ID: second.ID, Title: "second", Status: "completed"})
+	require.NoError(t, err)
+	// Измененная задача не записывается в кеш, а удаляется из него
+	_, err = cache.GetTask(ctx, second.ID)
+	assert.ErrorIs(t, err, domain.ErrCacheMiss)
+
+	completed := domain.TaskFilter{Statuses: []string{"completed"}}
+	tasks, err := s.GetAllTasks(ctx, completed)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, second.ID, tasks[0].ID)
+
+	version, err := cache.ListVersion(ctx)
+	require.NoError(t, err)
+	cached, err := cache.GetTaskList(ctx, version, completed)
+	require.NoError(t, err)
+	assert.Len(t, cached, 1)
+
+	tasks, err = s.GetAllTasks(ctx, domain.TaskFilter{})
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+	assert.ElementsMatch(t, []string{first.ID, second.ID}, []string{tasks[0].ID, tasks[1].ID})
+}
+
+// blockingStorage отдает задачу, прочитанную до сигнала release
+type blockingStorage struct {
+	TaskRepository
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingStorage) GetTaskByID(ctx context.Context, id string) (*domain.Task, error) {
+	close(s.started)
+	<-s.release
+	return &domain.Task{ID: id, Title: "old"}, nil
+}
+
+func (s *blockingStorage) UpdateTask(ctx context.Context, task *domain.Task) (*domain.Task, error) {
+	return task, nil
+}
+
+func TestGetTaskSkipsStaleFill(t *testing.T) {
+	storage := &blockingStorage{started: make(chan struct{}), release: make(chan struct{})}
+	cache := &mapCache{tasks: map[string]*domain.Task{}, missing: map[string]bool{}}
+	s := NewTaskService(storage, cache, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+
+	loaded := make(chan *domain.Task)
+	go func() {
+		task, err := s.GetTask(ctx, "1")
+		assert.NoError(t, err)
+		loaded <- task
+	}()
+
+	// Задачу меняют, пока ее загрузка в полете
+	<-storage.started
+	_, err := s.UpdateTask(ctx, &domain.Task{ID: "1", Title: "new", Status: "pending"})
+	require.NoError(t, err)
+	close(storage.release)
+	assert.Equal(t, "old", (<-loaded).Title)
+
+	_, err = cache.GetTask(ctx, "1")
+	assert.ErrorIs(t, err, domain.ErrCacheMiss, "stale task must not stay in cache")
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/SteepTaq/todo_project/internal/dbservice/domain"
+)
+
+const watchBuffer = 64
+
+// watchers рассылает изменения задач подписчикам внутри процесса
+type watchers struct {
+	mu   sync.Mutex
+	subs map[*watcher]struct{}
+}
+
+type watcher struct {
+	ch     chan domain.TaskChange
+	filter domain.TaskFilter
+}
+
+func newWatchers() *watchers {
+	return &watchers{subs: make(map[*watcher]struct{})}
+}
+
+func matches(f domain.TaskFilter, c domain.TaskChange) bool {
+	if c.Type == domain.TaskDeleted {
+		// Статус удаленной задачи неизвестен
+		return len(f.IDs) == 0 || slices.Contains(f.IDs, c.Task.ID)
+	}
+	return matchesTask(f, &c.Task)
+}
+
+func matchesTask(f domain.TaskFilter, task *domain.Task) bool {
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, task.Status) {
+		return false
+	}
+	if len(f.IDs) > 0 && !slices.Contains(f.IDs, task.ID) {
+		return false
+	}
+	return true
+}
+
+func (w *watchers) publish(c domain.TaskChange) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for sub := range w.subs {
+		if !matches(sub.filter, c) {
+			continue
+		}
+		select {
+		case sub.ch <- c:
+		default:
+			// Медленный подписчик: закрываем канал, пусть переподпишется
+			delete(w.subs, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+func (w *watchers) add(filter domain.TaskFilter) *watcher {
+	sub := &watcher{
+		ch:     make(chan domain.TaskChange, watchBuffer),
+		filter: filter,
+	}
+
+	w.mu.Lock()
+	w.subs[sub] = struct{}{}
+	w.mu.Unlock()
+
+	return sub
+}
+
+func (w *watchers) remove(sub *watcher) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.subs[sub]; ok {
+		delete(w.subs, sub)
+		close(sub.ch)
+	}
+}
+
+// Watch вызывает fn для каждого изменения задач, прошедшего фильтр, пока не
+// завершится контекст или fn не вернет ошибку. Возвращает domain.ErrWatchTooSlow,
+// если подписчик не успевает разбирать изменения.
+func (s *TaskService) Watch(ctx context.Context, filter domain.TaskFilter, fn func(domain.TaskChange) error) error {
+	sub := s.watchers.add(filter)
+	defer s.watchers.remove(sub)
+
+	s.logger(ctx).Debug("watcher subscribed", "statuses", filter.Statuses, "ids", filter.IDs)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case c, ok := <-sub.ch:
+			if !ok {
+				s.logger(ctx).Warn("watcher dropped: too slow")
+				return domain.ErrWatchTooSlow
+			}
+			if err := fn(c); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *TaskService) notify(changeType domain.ChangeType, task *domain.Task) {
+	s.watchers.publish(domain.TaskChange{
+		Type:       changeType,
+		Task:       *task,
+		OccurredAt: time.Now(),
+	})
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/SteepTaq/todo_project/pkg/conf"
+	"github.com/SteepTaq/todo_project/pkg/tracing"
+)
+
+type Config struct {
+	Kafka struct {
+		Brokers []string `mapstructure:"brokers"`
+		Topic   string   `mapstructure:"topic"`
+	} `mapstructure:"kafka"`
+
+	// Файл, в который пишутся полученные события и лог воркера
+	LogFile string `mapstructure:"log_file"`
+
+	Admin struct {
+		Port string `mapstructure:"port"`
+	} `mapstructure:"admin"`
+
+	Tracing tracing.Config `mapstructure:"tracing"`
+
+	Notifier struct {
+		Enabled    bool          `mapstructure:"enabled"`
+		From       string        `mapstructure:"from"`
+		BaseURL    string        `mapstructure:"base_url"`
+		FlushEvery time.Duration `mapstructure:"flush_every"`
+
+		SMTP struct {
+			Host     string        `mapstructure:"host"`
+			Port     string        `mapstructure:"port"`
+			Username string        `mapstructure:"username"`
+			Password string        `mapstructure:"password"`
+			Timeout  time.Duration `mapstructure:"timeout"`
+		} `mapstructure:"smtp"`
+
+		Recipients []struct {
+			Email  string   `mapstructure:"email"`
+			Name   string   `mapstructure:"name"`
+			Events []string `mapstructure:"events"`
+			Digest string   `mapstructure:"digest"`
+		} `mapstructure:"recipients"`
+	} `mapstructure:"notifier"`
+}
+
+// LoadConfig читает секцию worker. Любое поле можно переопределить
+// переменной окружения TODO_WORKER_<ключ>, например TODO_WORKER_KAFKA_BROKERS=kafka:9092.
+func LoadConfig() (*Config, error) {
+	var cfg Config
+	if err := conf.Load("worker", defaults(), &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func defaults() map[string]any {
+	return map[string]any{
+		"kafka": map[string]any{
+			"brokers": []string{"localhost:9094"},
+			"topic":   "events",
+		},
+		"log_file": "events.log",
+		"admin": map[string]any{
+			"port": "9092",
+		},
+		"tracing": tracing.Defaults(),
+		"notifier": map[string]any{
+			"enabled":     false,
+			"from":        "todo@localhost",
+			"base_url":    "http://localhost:8081",
+			"flush_every": "1m",
+			"smtp": map[string]any{
+				"host":     "localhost",
+				"port":     "1025",
+				"username": "",
+				"password": "",
+				"timeout":  "10s",
+			},
+			"recipients": []map[string]any{},
+		},
+	}
+}
+
+func (c *Config) Validate() error {
+	v := conf.NewValidator("worker")
+
+	v.Addresses("kafka.brokers", c.Kafka.Brokers)
+	v.Required("kafka.topic", c.Kafka.Topic)
+	v.Required("log_file", c.LogFile)
+	v.Port("admin.port", c.Admin.Port)
+	c.Tracing.Validate(v.Sub("tracing"))
+
+	if n := c.Notifier; n.Enabled {
+		notifier := v.Sub("notifier")
+		notifier.Required("from", n.From)
+		notifier.Duration("flush_every", n.FlushEvery)
+		notifier.Required("smtp.host", n.SMTP.Host)
+		notifier.Port("smtp.port", n.SMTP.Port)
+		notifier.Duration("smtp.timeout", n.SMTP.Timeout)
+		for i, r := range n.Recipients {
+			recipient := notifier.Sub(fmt.Sprintf("recipients[%d]", i))
+			recipient.Required("email", r.Email)
+			for j, event := range r.Events {
+				recipient.OneOf(fmt.Sprintf("events[%d]", j), event, "task_overdue", "task_assigned", "task_completed")
+			}
+			if r.Digest != "" {
+				recipient.OneOf("digest", r.Digest, "immediate", "hourly", "daily")
+			}
+		}
+	}
+
+	return v.Err()
+}
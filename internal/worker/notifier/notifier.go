@@ -0,0 +1,272 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Назначение и завершение публикует API сервис, просрочку воркер определяет
+// сам по срокам задач из событий создания и изменения
+const (
+	EventTaskOverdue   = "task_overdue"
+	EventTaskAssigned  = "task_assigned"
+	EventTaskCompleted = "task_completed"
+)
+
+// DigestMode определяет, как часто получатель получает письма
+type DigestMode string
+
+const (
+	DigestImmediate DigestMode = "immediate"
+	DigestHourly    DigestMode = "hourly"
+	DigestDaily     DigestMode = "daily"
+)
+
+func (m DigestMode) period() time.Duration {
+	switch m {
+	case DigestHourly:
+		return time.Hour
+	case DigestDaily:
+		return 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// Event - событие из Kafka в формате, который публикует API сервис
+type Event struct {
+	Event string `json:"event"`
+	Task  Task   `json:"task"`
+}
+
+type Task struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Status      string     `json:"status"`
+	Assignee    string     `json:"assignee,omitempty"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Preference - настройки уведомлений одного пользователя
+type Preference struct {
+	Email string
+	Name  string
+	// События, на которые пользователь подписался явно. Пустой список - без писем.
+	Events []string
+	Digest DigestMode
+}
+
+// wants - подписан ли пользователь на событие. Письма о задаче с исполнителем
+// получает только сам исполнитель.
+func (p Preference) wants(e Event) bool {
+	if !slices.Contains(p.Events, e.Event) {
+		return false
+	}
+	return e.Task.Assignee == "" || strings.EqualFold(e.Task.Assignee, p.Email)
+}
+
+type Message struct {
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+type Notifier struct {
+	sender  Sender
+	prefs   []Preference
+	baseURL string
+	log     *slog.Logger
+	now     func() time.Time
+
+	mu        sync.Mutex
+	pending   map[string][]Event
+	lastFlush map[DigestMode]time.Time
+	// Незавершенные задачи со сроком, по которым еще не было письма о просрочке
+	deadlines map[string]Task
+}
+
+func New(sender Sender, prefs []Preference, baseURL string, logger *slog.Logger) *Notifier {
+	now := time.Now()
+	return &Notifier{
+		sender:  sender,
+		prefs:   prefs,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		log:     logger.With("component", "notifier"),
+		now:     time.Now,
+		pending: make(map[string][]Event),
+		lastFlush: map[DigestMode]time.Time{
+			DigestHourly: now,
+			DigestDaily:  now,
+		},
+		deadlines: make(map[string]Task),
+	}
+}
+
+// Handle разбирает сообщение из Kafka и отправляет письма тем, кто на него подписан.
+// События, для которых нет шаблона, только обновляют отслеживаемые сроки.
+func (n *Notifier) Handle(ctx context.Context, payload []byte) error {
+	var e Event
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+	n.track(e)
+	if _, ok := subjects[e.Event]; !ok {
+		return nil
+	}
+	return n.dispatch(ctx, e)
+}
+
+// track запоминает срок задачи из событий API. Завершенные и удаленные задачи
+// больше не отслеживаются.
+func (n *Notifier) track(e Event) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	switch e.Event {
+	case "task_created", "task_updated":
+		if e.Task.DueAt != nil && e.Task.Status != "TASK_STATUS_COMPLETED" {
+			n.deadlines[e.Task.ID] = e.Task
+		} else {
+			delete(n.deadlines, e.Task.ID)
+		}
+	case "task_deleted":
+		delete(n.deadlines, e.Task.ID)
+	}
+}
+
+// CheckOverdue отправляет письма о задачах, срок которых истек. О каждой
+// задаче письмо уходит один раз, пока срок не изменят.
+func (n *Notifier) CheckOverdue(ctx context.Context) error {
+	n.mu.Lock()
+	var overdue []Event
+	now := n.now()
+	for id, task := range n.deadlines {
+		if task.DueAt.After(now) {
+			continue
+		}
+		overdue = append(overdue, Event{Event: EventTaskOverdue, Task: task})
+		delete(n.deadlines, id)
+	}
+	n.mu.Unlock()
+
+	var errs []error
+	for _, e := range overdue {
+		if err := n.dispatch(ctx, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (n *Notifier) dispatch(ctx context.Context, e Event) error {
+	var errs []error
+	for _, p := range n.prefs {
+		if !p.wants(e) {
+			continue
+		}
+		if p.Digest.period() > 0 {
+			n.mu.Lock()
+			n.pending[p.Email] = append(n.pending[p.Email], e)
+			n.mu.Unlock()
+			continue
+		}
+
+		msg, err := renderEvent(e, p, n.baseURL)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := n.sender.Send(ctx, msg); err != nil {
+			errs = append(errs, fmt.Errorf("failed to notify %s: %w", p.Email, err))
+			continue
+		}
+		n.log.Info("notification sent", "event", e.Event, "task_id", e.Task.ID, "to", p.Email)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Flush отправляет накопленные дайджесты всем получателям с указанным режимом
+func (n *Notifier) Flush(ctx context.Context, mode DigestMode) error {
+	n.mu.Lock()
+	batches := make(map[string][]Event)
+	for _, p := range n.prefs {
+		if p.Digest != mode || len(n.pending[p.Email]) == 0 {
+			continue
+		}
+		batches[p.Email] = n.pending[p.Email]
+		delete(n.pending, p.Email)
+	}
+	n.lastFlush[mode] = n.now()
+	n.mu.Unlock()
+
+	var errs []error
+	for _, p := range n.prefs {
+		events, ok := batches[p.Email]
+		if !ok {
+			continue
+		}
+		msg, err := renderDigest(events, p, n.baseURL)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := n.sender.Send(ctx, msg); err != nil {
+			errs = append(errs, fmt.Errorf("failed to send digest to %s: %w", p.Email, err))
+			continue
+		}
+		n.log.Info("digest sent", "mode", mode, "to", p.Email, "events", len(events))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Run периодически проверяет просроченные задачи и отправляет дайджесты, у которых
+// истек период. При завершении контекста оставшиеся дайджесты отправляются сразу.
+func (n *Notifier) Run(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			for _, mode := range []DigestMode{DigestHourly, DigestDaily} {
+				if err := n.Flush(flushCtx, mode); err != nil {
+					n.log.Error("failed to flush digest on shutdown", "mode", mode, "error", err)
+				}
+			}
+			cancel()
+			return
+		case <-ticker.C:
+			if err := n.CheckOverdue(ctx); err != nil {
+				n.log.Error("failed to notify about overdue tasks", "error", err)
+			}
+			for _, mode := range []DigestMode{DigestHourly, DigestDaily} {
+				n.mu.Lock()
+				due := n.now().Sub(n.lastFlush[mode]) >= mode.period()
+				n.mu.Unlock()
+				if !due {
+					continue
+				}
+				if err := n.Flush(ctx, mode); err != nil {
+					n.log.Error("failed to flush digest", "mode", mode, "error", err)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMTP - минимальный SMTP сервер, который складывает полученные письма в память
+type fakeSMTP struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	messages []fakeMail
+}
+
+type fakeMail struct {
+	From string
+	To   []string
+	Data string
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &fakeSMTP{listener: l}
+	go s.serve()
+	t.Cleanup(func() { l.Close() })
+
+	return s
+}
+
+func (s *fakeSMTP) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTP) handle(conn net.Conn) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 fake ESMTP")
+
+	var mail fakeMail
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch cmd {
+		case "EHLO", "HELO":
+			tp.PrintfLine("250 fake")
+		case "MAIL":
+			mail = fakeMail{From: line}
+			tp.PrintfLine("250 OK")
+		case "RCPT":
+			mail.To = append(mail.To, line)
+			tp.PrintfLine("250 OK")
+		case "DATA":
+			tp.PrintfLine("354 go ahead")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			mail.Data = string(data)
+			s.mu.Lock()
+			s.messages = append(s.messages, mail)
+			s.mu.Unlock()
+			tp.PrintfLine("250 OK")
+		case "QUIT":
+			tp.PrintfLine("221 bye")
+			return
+		default:
+			tp.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func (s *fakeSMTP) Messages() []fakeMail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]fakeMail(nil), s.messages...)
+}
+
+func newTestNotifier(t *testing.T, prefs ...Preference) (*Notifier, *fakeSMTP) {
+	srv := newFakeSMTP(t)
+	host, port, err := net.SplitHostPort(srv.listener.Addr().String())
+	require.NoError(t, err)
+
+	sender := NewSMTPSender(host, port, "", "", "todo@localhost", 5*time.Second)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	return New(sender, prefs, "http://todo.local", logger), srv
+}
+
+func event(t *testing.T, name, title string) []byte {
+	data, err := json.Marshal(Event{
+		Event: name,
+		Task:  Task{ID: "task-1", Title: title},
+	})
+	require.NoError(t, err)
+	return data
+}
+
+func TestHandleSendsImmediateEmail(t *testing.T) {
+	n, srv := newTestNotifier(t, Preference{
+		Email:  "alice@example.com",
+		Name:   "Alice",
+		Events: []string{EventTaskCompleted},
+		Digest: DigestImmediate,
+	})
+
+	err := n.Handle(context.Background(), event(t, EventTaskCompleted, "Write report"))
+	require.NoError(t, err)
+
+	msgs := srv.Messages()
+	require.Len(t, msgs, 1)
+	assert.Contains(t, msgs[0].To[0], "alice@example.com")
+	assert.Contains(t, msgs[0].Data, "Subject: Task completed: Write report")
+	assert.Contains(t, msgs[0].Data, "text/plain")
+	assert.Contains(t, msgs[0].Data, "text/html")
+	assert.Contains(t, msgs[0].Data, "http://todo.local/list/task-1")
+}
+
+func TestHandleRespectsPreferences(t *testing.T) {
+	n, srv := newTestNotifier(t,
+		Preference{Email: "alice@example.com", Events: []string{"task_created"}, Digest: DigestImmediate},
+		// Без подписок писем нет
+		Preference{Email: "bob@example.com", Digest: DigestImmediate},
+	)
+
+	require.NoError(t, n.Handle(context.Background(), event(t, EventTaskCompleted, "Not subscribed")))
+	require.NoError(t, n.Handle(context.Background(), event(t, "task_created", "No template")))
+
+	assert.Empty(t, srv.Messages())
+}
+
+func TestDigestBatchesEvents(t *testing.T) {
+	n, srv := newTestNotifier(t, Preference{
+		Email:  "alice@example.com",
+		Events: []string{EventTaskCompleted},
+		Digest: DigestHourly,
+	})
+
+	require.NoError(t, n.Handle(context.Background(), event(t, EventTaskCompleted, "First")))
+	require.NoError(t, n.Handle(context.Background(), event(t, EventTaskCompleted, "Second")))
+	assert.Empty(t, srv.Messages())
+
+	require.NoError(t, n.Flush(context.Background(), DigestDaily))
+	assert.Empty(t, srv.Messages())
+
+	require.NoError(t, n.Flush(context.Background(), DigestHourly))
+	msgs := srv.Messages()
+	require.Len(t, msgs, 1)
+	assert.Contains(t, msgs[0].Data, "2 updates")
+	assert.Contains(t, msgs[0].Data, "First")
+	assert.Contains(t, msgs[0].Data, "Second")
+
+	require.NoError(t, n.Flush(context.Background(), DigestHourly))
+	assert.Len(t, srv.Messages(), 1)
+}
+
+func TestCheckOverdueNotifiesOnce(t *testing.T) {
+	n, srv := newTestNotifier(t, Preference{
+		Email:  "alice@example.com",
+		Events: []string{EventTaskOverdue},
+		Digest: DigestImmediate,
+	})
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	n.now = func() time.Time { return now }
+
+	taskEvent := func(name, id, status string, due time.Time) []byte {
+		data, err := json.Marshal(Event{
+			Event: name,
+			Task:  Task{ID: id, Title: id, Status: status, DueAt: &due},
+		})
+		require.NoError(t, err)
+		return data
+	}
+	ctx := context.Background()
+	require.NoError(t, n.Handle(ctx, taskEvent("task_created", "late", "TASK_STATUS_PENDING", now.Add(time.Hour))))
+	require.NoError(t, n.Handle(ctx, taskEvent("task_created", "done", "TASK_STATUS_PENDING", now.Add(time.Hour))))
+	require.NoError(t, n.Handle(ctx, taskEvent("task_updated", "done", "TASK_STATUS_COMPLETED", now.Add(time.Hour))))
+	require.NoError(t, n.Handle(ctx, taskEvent("task_created", "later", "TASK_STATUS_PENDING", now.Add(3*time.Hour))))
+
+	require.NoError(t, n.CheckOverdue(ctx))
+	assert.Empty(t, srv.Messages())
+
+	now = now.Add(2 * time.Hour)
+	require.NoError(t, n.CheckOverdue(ctx))
+	require.NoError(t, n.CheckOverdue(ctx))
+
+	msgs := srv.Messages()
+	require.Len(t, msgs, 1)
+	assert.Contains(t, msgs[0].Data, "Subject: Task overdue: late")
+	assert.Contains(t, msgs[0].Data, "Due: 2026-10-19 13:00 UTC")
+}
+
+func TestAssignedTaskNotifiesOnlyAssignee(t *testing.T) {
+	n, srv := newTestNotifier(t,
+		Preference{Email: "alice@example.com", Events: []string{EventTaskAssigned}, Digest: DigestImmediate},
+		Preference{Email: "bob@example.com", Events: []string{EventTaskAssigned}, Digest: DigestImmediate},
+	)
+
+	data, err := json.Marshal(Event{
+		Event: EventTaskAssigned,
+		Task:  Task{ID: "task-1", Title: "Review", Assignee: "Bob@example.com"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, n.Handle(context.Background(), data))
+
+	msgs := srv.Messages()
+	require.Len(t, msgs, 1)
+	assert.Contains(t, msgs[0].To[0], "bob@example.com")
+	assert.Contains(t, msgs[0].Data, "Subject: Task assigned to you: Review")
+}
@@ -0,0 +1,119 @@
+package notifier
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"text/template"
+)
+
+var subjects = map[string]string{
+	EventTaskOverdue:   "Task overdue: %s",
+	EventTaskAssigned:  "Task assigned to you: %s",
+	EventTaskCompleted: "Task completed: %s",
+}
+
+var headlines = map[string]string{
+	EventTaskOverdue:   "is overdue",
+	EventTaskAssigned:  "was assigned to you",
+	EventTaskCompleted: "was completed",
+}
+
+var funcs = template.FuncMap{
+	"headline": func(event string) string { return headlines[event] },
+}
+
+var (
+	eventText = template.Must(template.New("event").Funcs(funcs).Parse(
+		`Hi {{.Name}},
+
+Task "{{.Event.Task.Title}}" {{headline .Event.Event}}.
+{{- with .Event.Task.DueAt}}
+Due: {{.Format "2006-01-02 15:04 MST"}}{{end}}
+{{- with .Event.Task.Description}}
+
+{{.}}{{end}}
+
+{{.BaseURL}}/list/{{.Event.Task.ID}}
+`))
+
+	eventHTML = htmltemplate.Must(htmltemplate.New("event").Funcs(htmltemplate.FuncMap(funcs)).Parse(
+		`<p>Hi {{.Name}},</p>
+<p>Task <a href="{{.BaseURL}}/list/{{.Event.Task.ID}}">{{.Event.Task.Title}}</a> {{headline .Event.Event}}.</p>
+{{- with .Event.Task.DueAt}}
+<p>Due: {{.Format "2006-01-02 15:04 MST"}}</p>{{end}}
+{{- with .Event.Task.Description}}
+<p>{{.}}</p>{{end}}
+`))
+
+	digestText = template.Must(template.New("digest").Funcs(funcs).Parse(
+		`Hi {{.Name}},
+
+Here is what happened since the last digest:
+{{range .Events}}
+- "{{.Task.Title}}" {{headline .Event}}: {{$.BaseURL}}/list/{{.Task.ID}}{{end}}
+`))
+
+	digestHTML = htmltemplate.Must(htmltemplate.New("digest").Funcs(htmltemplate.FuncMap(funcs)).Parse(
+		`<p>Hi {{.Name}},</p>
+<p>Here is what happened since the last digest:</p>
+<ul>
+{{- range .Events}}
+<li><a href="{{$.BaseURL}}/list/{{.Task.ID}}">{{.Task.Title}}</a> {{headline .Event}}</li>
+{{- end}}
+</ul>
+`))
+)
+
+func renderEvent(e Event, p Preference, baseURL string) (Message, error) {
+	data := struct {
+		Name    string
+		Event   Event
+		BaseURL string
+	}{displayName(p), e, baseURL}
+
+	var text, html bytes.Buffer
+	if err := eventText.Execute(&text, data); err != nil {
+		return Message{}, fmt.Errorf("failed to render text template: %w", err)
+	}
+	if err := eventHTML.Execute(&html, data); err != nil {
+		return Message{}, fmt.Errorf("failed to render html template: %w", err)
+	}
+
+	return Message{
+		To:      []string{p.Email},
+		Subject: fmt.Sprintf(subjects[e.Event], e.Task.Title),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+func renderDigest(events []Event, p Preference, baseURL string) (Message, error) {
+	data := struct {
+		Name    string
+		Events  []Event
+		BaseURL string
+	}{displayName(p), events, baseURL}
+
+	var text, html bytes.Buffer
+	if err := digestText.Execute(&text, data); err != nil {
+		return Message{}, fmt.Errorf("failed to render text template: %w", err)
+	}
+	if err := digestHTML.Execute(&html, data); err != nil {
+		return Message{}, fmt.Errorf("failed to render html template: %w", err)
+	}
+
+	return Message{
+		To:      []string{p.Email},
+		Subject: fmt.Sprintf("Your %s todo digest: %d updates", p.Digest, len(events)),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+func displayName(p Preference) string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.Email
+}
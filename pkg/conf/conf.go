@@ -0,0 +1,104 @@
+// Package conf загружает секцию общего configs/config.yml поверх значений по
+// умолчанию и переменных окружения.
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix - префикс переменных окружения: db_service.postgres.password
+// задается через TODO_DB_SERVICE_POSTGRES_PASSWORD
+const EnvPrefix = "TODO"
+
+// FileEnv - путь к файлу конфигурации вместо поиска config.yml в . и ./configs
+const FileEnv = EnvPrefix + "_CONFIG_FILE"
+
+// Validatable проверяет загруженную конфигурацию
+type Validatable interface {
+	Validate() error
+}
+
+// Load заполняет out секцией section. Порядок приоритетов: значения по
+// умолчанию, файл, переменные окружения, файлы секретов (<переменная>_FILE,
+// например TODO_DB_SERVICE_POSTGRES_PASSWORD_FILE=/run/secrets/pg_password).
+// Без файла конфигурации сервис запускается на значениях по умолчанию.
+// Ошибки чтения и проверки возвращаются все сразу.
+func Load(section string, defaults map[string]any, out Validatable) error {
+	v := newViper(section, defaults)
+	if err := readFile(v); err != nil {
+		return err
+	}
+	settings, err := sectionSettings(v, section)
+	return errors.Join(err, decode(section, settings, out))
+}
+
+func newViper(section string, defaults map[string]any) *viper.Viper {
+	v := viper.New()
+	v.SetDefault(section, defaults)
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	if file := os.Getenv(FileEnv); file != "" {
+		v.SetConfigFile(file)
+	} else {
+		v.SetConfigName("config")
+		v.SetConfigType("yaml")
+		v.AddConfigPath(".")
+		v.AddConfigPath("./configs")
+	}
+	return v
+}
+
+func readFile(v *viper.Viper) error {
+	if err := v.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) {
+			return fmt.Errorf("failed to read config: %w", err)
+		}
+	}
+	return nil
+}
+
+// sectionSettings возвращает значения секции с учетом переменных окружения и файлов секретов
+func sectionSettings(v *viper.Viper, section string) (map[string]any, error) {
+	var errs []error
+	for _, key := range v.AllKeys() {
+		env := EnvName(key) + "_FILE"
+		path, ok := os.LookupEnv(env)
+		if !ok {
+			continue
+		}
+		secret, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", env, err))
+			continue
+		}
+		v.Set(key, strings.TrimSpace(string(secret)))
+	}
+
+	// Переменные окружения учитываются только через AllSettings, а не через Sub
+	settings, _ := v.AllSettings()[section].(map[string]any)
+	return settings, errors.Join(errs...)
+}
+
+func decode(section string, settings map[string]any, out Validatable) error {
+	sub := viper.New()
+	if err := sub.MergeConfigMap(settings); err != nil {
+		return fmt.Errorf("failed to merge %q config: %w", section, err)
+	}
+	if err := sub.Unmarshal(out); err != nil {
+		return fmt.Errorf("failed to unmarshal %q config: %w", section, err)
+	}
+	return out.Validate()
+}
+
+// EnvName - имя переменной окружения для ключа конфигурации
+func EnvName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
@@ -0,0 +1,120 @@
+package conf
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testConfig struct {
+	Port     string        `mapstructure:"port"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+	Brokers  []string      `mapstructure:"brokers"`
+	Password string        `mapstructure:"password"`
+}
+
+func (c *testConfig) Validate() error {
+	v := NewValidator("svc")
+	v.Port("port", c.Port)
+	v.Duration("timeout", c.Timeout)
+	v.Addresses("brokers", c.Brokers)
+	return v.Err()
+}
+
+var testDefaults = map[string]any{
+	"port":     "8080",
+	"timeout":  "1s",
+	"brokers":  []string{"localhost:9092"},
+	"password": "",
+}
+
+func writeConfig(t *testing.T, content string) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	t.Setenv(FileEnv, path)
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	writeConfig(t, "svc:\n  port: '9000'\n  timeout: '5s'\nother:\n  port: '1'\n")
+	secret := filepath.Join(t.TempDir(), "password")
+	require.NoError(t, os.WriteFile(secret, []byte("s3cret\n"), 0o600))
+	t.Setenv("TODO_SVC_TIMEOUT", "7s")
+	t.Setenv("TODO_SVC_BROKERS", "a:1,b:2")
+	t.Setenv("TODO_SVC_PASSWORD_FILE", secret)
+
+	var cfg testConfig
+	require.NoError(t, Load("svc", testDefaults, &cfg))
+
+	assert.Equal(t, "9000", cfg.Port)
+	assert.Equal(t, 7*time.Second, cfg.Timeout)
+	assert.Equal(t, []string{"a:1", "b:2"}, cfg.Brokers)
+	assert.Equal(t, "s3cret", cfg.Password)
+}
+
+func TestLoadWithoutFile(t *testing.T) {
+	t.Setenv(FileEnv, "")
+	t.Chdir(t.TempDir())
+
+	var cfg testConfig
+	require.NoError(t, Load("svc", testDefaults, &cfg))
+	assert.Equal(t, "8080", cfg.Port)
+	assert.Equal(t, time.Second, cfg.Timeout)
+}
+
+func TestLoadAggregatesErrors(t *testing.T) {
+	writeConfig(t, "svc:\n  port: 'http'\n  timeout: '-1s'\n  brokers: []\n")
+	t.Setenv("TODO_SVC_PASSWORD_FILE", filepath.Join(t.TempDir(), "missing"))
+
+	var cfg testConfig
+	err := Load("svc", testDefaults, &cfg)
+	require.Error(t, err)
+	for _, msg := range []string{
+		"TODO_SVC_PASSWORD_FILE",
+		`svc.port: invalid port "http"`,
+		"svc.timeout: must be positive",
+		"svc.brokers: at least one address is required",
+	} {
+		assert.Contains(t, err.Error(), msg)
+	}
+}
+
+func TestWatchAppliesLiveKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	write := func(content string) {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	write("svc:\n  port: '9000'\n  timeout: '5s'\n")
+	t.Setenv(FileEnv, path)
+
+	var cfg testConfig
+	require.NoError(t, Load("svc", testDefaults, &cfg))
+	holder := NewHolder(&cfg)
+	reloaded := make(chan *testConfig, 10)
+	holder.Subscribe(func(prev, cfg *testConfig) { reloaded <- cfg })
+	require.NoError(t, Watch("svc", testDefaults, []string{"timeout"}, holder, slog.New(slog.DiscardHandler)))
+
+	// Порт применяется только после перезапуска
+	write("svc:\n  port: '9001'\n  timeout: '6s'\n")
+	select {
+	case got := <-reloaded:
+		assert.Equal(t, 6*time.Second, got.Timeout)
+		assert.Equal(t, "9000", got.Port)
+		assert.Same(t, got, holder.Load())
+	case <-time.After(5 * time.Second):
+		t.Fatal("config was not reloaded")
+	}
+
+	// Файл с ошибкой не применяется
+	write("svc:\n  port: '9001'\n  timeout: '-1s'\n")
+	select {
+	case got := <-reloaded:
+		t.Fatalf("invalid config applied: %+v", got)
+	case <-time.After(300 * time.Millisecond):
+	}
+	assert.Equal(t, 6*time.Second, holder.Load().Timeout)
+}
@@ -0,0 +1,107 @@
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validator собирает ошибки проверки конфигурации, чтобы сообщить обо всех сразу.
+// Поля называются полными ключами, как в config.yml: db_service.postgres.port.
+type Validator struct {
+	prefix string
+	errs   *[]error
+}
+
+func NewValidator(section string) *Validator {
+	return &Validator{prefix: section, errs: new([]error)}
+}
+
+// Sub - проверка вложенной секции с теми же накопленными ошибками
+func (v *Validator) Sub(name string) *Validator {
+	return &Validator{prefix: v.key(name), errs: v.errs}
+}
+
+func (v *Validator) key(field string) string {
+	if v.prefix == "" {
+		return field
+	}
+	return v.prefix + "." + field
+}
+
+func (v *Validator) Errorf(field, format string, args ...any) {
+	*v.errs = append(*v.errs, fmt.Errorf("%s: %s", v.key(field), fmt.Sprintf(format, args...)))
+}
+
+func (v *Validator) Err() error {
+	return errors.Join(*v.errs...)
+}
+
+func (v *Validator) Required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.Errorf(field, "is required")
+	}
+}
+
+func (v *Validator) Port(field, value string) {
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 1 || port > 65535 {
+		v.Errorf(field, "invalid port %q", value)
+	}
+}
+
+// Address проверяет адрес вида host:port, host может быть пустым
+func (v *Validator) Address(field, value string) {
+	_, port, err := net.SplitHostPort(value)
+	if err != nil {
+		v.Errorf(field, "invalid address %q: %v", value, err)
+		return
+	}
+	v.Port(field, port)
+}
+
+// Addresses проверяет непустой список адресов host:port
+func (v *Validator) Addresses(field string, values []string) {
+	if len(values) == 0 {
+		v.Errorf(field, "at least one address is required")
+	}
+	for _, value := range values {
+		v.Address(field, value)
+	}
+}
+
+// Duration проверяет, что длительность больше нуля
+func (v *Validator) Duration(field string, d time.Duration) {
+	if d <= 0 {
+		v.Errorf(field, "must be positive, got %v", d)
+	}
+}
+
+func (v *Validator) Positive(field string, n int) {
+	if n <= 0 {
+		v.Errorf(field, "must be positive, got %d", n)
+	}
+}
+
+func (v *Validator) NonNegative(field string, n float64) {
+	if n < 0 {
+		v.Errorf(field, "must not be negative, got %v", n)
+	}
+}
+
+// Fraction проверяет долю от 0 до 1
+func (v *Validator) Fraction(field string, f float64) {
+	if f < 0 || f > 1 {
+		v.Errorf(field, "must be between 0 and 1, got %v", f)
+	}
+}
+
+func (v *Validator) OneOf(field, value string, allowed ...string) {
+	if !slices.Contains(allowed, value) {
+		v.Errorf(field, "must be one of %s, got %q", strings.Join(allowed, ", "), value)
+	}
+}
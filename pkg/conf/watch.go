@@ -0,0 +1,188 @@
+package conf
+
+import (
+	"errors"
+	"log/slog"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const settleDelay = 100 * time.Millisecond
+
+// Holder хранит текущую конфигурацию. Load безопасен из любых горутин,
+// подписчики вызываются после каждой примененной перезагрузки.
+type Holder[T any] struct {
+	current atomic.Pointer[T]
+
+	mu   sync.Mutex
+	subs []func(prev, cfg *T)
+}
+
+func NewHolder[T any](cfg *T) *Holder[T] {
+	h := &Holder[T]{}
+	h.current.Store(cfg)
+	return h
+}
+
+func (h *Holder[T]) Load() *T {
+	return h.current.Load()
+}
+
+// Subscribe регистрирует fn, которая получает прежнюю и новую конфигурацию после перезагрузки
+func (h *Holder[T]) Subscribe(fn func(prev, cfg *T)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subs = append(h.subs, fn)
+}
+
+func (h *Holder[T]) store(cfg *T) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	prev := h.current.Swap(cfg)
+	for _, fn := range h.subs {
+		fn(prev, cfg)
+	}
+}
+
+// Watch перечитывает файл конфигурации при изменении и применяет к holder
+// только ключи из live: сам ключ или все под ним, например "logger.level" или
+// "rate_limit". Об изменении остальных ключей пишется, что нужен перезапуск.
+// Файл с ошибками не применяется целиком.
+func Watch[T any, PT interface {
+	*T
+	Validatable
+}](section string, defaults map[string]any, live []string, holder *Holder[T], log *slog.Logger) error {
+	log = log.With("component", "config_watcher")
+
+	v := newViper(section, defaults)
+	if err := readFile(v); err != nil {
+		return err
+	}
+	if v.ConfigFileUsed() == "" {
+		log.Info("no config file to watch")
+		return nil
+	}
+	settings, err := sectionSettings(v, section)
+	if err != nil {
+		return err
+	}
+	applied := flatten(settings, "")
+
+	var mu sync.Mutex
+	v.OnConfigChange(func(fsnotify.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// Событие приходит и на обрезание файла перед записью: viper мог прочитать
+		// его пустым, поэтому даем записи завершиться и читаем заново
+		time.Sleep(settleDelay)
+		if err := v.ReadInConfig(); err != nil {
+			log.Error("config reload failed", "file", v.ConfigFileUsed(), "error", err)
+			return
+		}
+		settings, err := sectionSettings(v, section)
+		if err := errors.Join(err, decode(section, settings, PT(new(T)))); err != nil {
+			log.Error("config reload rejected", "file", v.ConfigFileUsed(), "error", err)
+			return
+		}
+		next := flatten(settings, "")
+
+		merged := maps.Clone(applied)
+		var changed, restart []string
+		for _, key := range changedKeys(applied, next) {
+			if !isLive(key, live) {
+				restart = append(restart, key)
+				continue
+			}
+			changed = append(changed, key)
+			if value, ok := next[key]; ok {
+				merged[key] = value
+			} else {
+				delete(merged, key)
+			}
+		}
+		if len(restart) > 0 {
+			log.Warn("config changes require restart", "keys", restart)
+		}
+		if len(changed) == 0 {
+			return
+		}
+
+		cfg := PT(new(T))
+		if err := decode(section, unflatten(merged), cfg); err != nil {
+			log.Error("config reload rejected", "file", v.ConfigFileUsed(), "error", err)
+			return
+		}
+		applied = merged
+		holder.store((*T)(cfg))
+		log.Info("config reloaded", "changed", changed)
+	})
+	v.WatchConfig()
+	return nil
+}
+
+func isLive(key string, live []string) bool {
+	for _, prefix := range live {
+		if key == prefix || strings.HasPrefix(key, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func changedKeys(old, next map[string]any) []string {
+	var keys []string
+	for key, value := range next {
+		if prev, ok := old[key]; !ok || !reflect.DeepEqual(prev, value) {
+			keys = append(keys, key)
+		}
+	}
+	for key := range old {
+		if _, ok := next[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// flatten превращает вложенные секции в ключи вида rate_limit.groups.tasks.burst
+func flatten(settings map[string]any, prefix string) map[string]any {
+	flat := make(map[string]any)
+	for key, value := range settings {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]any); ok && len(nested) > 0 {
+			maps.Copy(flat, flatten(nested, key))
+			continue
+		}
+		flat[key] = value
+	}
+	return flat
+}
+
+func unflatten(flat map[string]any) map[string]any {
+	settings := make(map[string]any)
+	for key, value := range flat {
+		parts := strings.Split(key, ".")
+		m := settings
+		for _, part := range parts[:len(parts)-1] {
+			nested, ok := m[part].(map[string]any)
+			if !ok {
+				nested = make(map[string]any)
+				m[part] = nested
+			}
+			m = nested
+		}
+		m[parts[len(parts)-1]] = value
+	}
+	return settings
+}
@@ -7,6 +7,30 @@ import (
 
 type key struct{}
 
+type requestInfoKey struct{}
+
+// RequestInfo - сведения о запросе, которые передаются между сервисами
+type RequestInfo struct {
+	RequestID string
+	UserID    string
+	TenantID  string
+}
+
+// Attrs возвращает непустые поля в виде атрибутов для slog
+func (i RequestInfo) Attrs() []any {
+	var attrs []any
+	if i.RequestID != "" {
+		attrs = append(attrs, "request_id", i.RequestID)
+	}
+	if i.UserID != "" {
+		attrs = append(attrs, "user_id", i.UserID)
+	}
+	if i.TenantID != "" {
+		attrs = append(attrs, "tenant_id", i.TenantID)
+	}
+	return attrs
+}
+
 // WithLogger добавляет логгер в контекст
 func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
 	return context.WithValue(ctx, key{}, logger)
@@ -14,8 +38,24 @@ func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
 
 // LoggerFromContext возвращает логгер из контекста
 func LoggerFromContext(ctx context.Context) *slog.Logger {
+	return LoggerFromContextOr(ctx, slog.Default())
+}
+
+// LoggerFromContextOr возвращает логгер из контекста или fallback, если логгера в контексте нет
+func LoggerFromContextOr(ctx context.Context, fallback *slog.Logger) *slog.Logger {
 	if logger, ok := ctx.Value(key{}).(*slog.Logger); ok {
 		return logger
 	}
-	return slog.Default()
+	return fallback
+}
+
+// WithRequestInfo добавляет сведения о запросе в контекст
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// RequestInfoFromContext возвращает сведения о запросе из контекста
+func RequestInfoFromContext(ctx context.Context) RequestInfo {
+	info, _ := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return info
 }
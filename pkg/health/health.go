@@ -0,0 +1,133 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/SteepTaq/todo_project/pkg/response"
+)
+
+type Check struct {
+	Name    string
+	Timeout time.Duration
+	Func    func(ctx context.Context) error
+	// Optional - отказ показывается как "degraded" и не снимает готовность
+	Optional bool
+}
+
+type Result struct {
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Duration  string    `json:"duration"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Checker выполняет проверки зависимостей параллельно и кеширует результаты на ttl,
+// чтобы частые запросы probe не нагружали зависимости
+type Checker struct {
+	checks []Check
+	ttl    time.Duration
+
+	mu    sync.Mutex
+	cache map[string]Result
+}
+
+func NewChecker(ttl time.Duration, checks ...Check) *Checker {
+	return &Checker{
+		checks: checks,
+		ttl:    ttl,
+		cache:  make(map[string]Result),
+	}
+}
+
+// Run возвращает результаты всех проверок и признак, что все обязательные прошли успешно
+func (c *Checker) Run(ctx context.Context) (map[string]Result, bool) {
+	results := make(map[string]Result, len(c.checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := c.run(ctx, check)
+			mu.Lock()
+			results[check.Name] = r
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	ok := true
+	for _, r := range results {
+		if r.Status == "fail" {
+			ok = false
+		}
+	}
+	return results, ok
+}
+
+func (c *Checker) run(ctx context.Context, check Check) Result {
+	c.mu.Lock()
+	cached, found := c.cache[check.Name]
+	c.mu.Unlock()
+	if found && time.Since(cached.CheckedAt) < c.ttl {
+		return cached
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, check.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Func(ctx)
+	r := Result{
+		Status:    "ok",
+		Duration:  time.Since(start).String(),
+		CheckedAt: start,
+	}
+	if err != nil {
+		r.Status = "fail"
+		if check.Optional {
+			r.Status = "degraded"
+		}
+		r.Error = err.Error()
+	}
+
+	c.mu.Lock()
+	c.cache[check.Name] = r
+	c.mu.Unlock()
+
+	return r
+}
+
+// ReadyHandler отвечает 200, если все обязательные проверки прошли, и 503 иначе.
+// В теле - общий статус (ok, degraded или fail) и разбивка по проверкам.
+func (c *Checker) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		results, ok := c.Run(r.Context())
+
+		status, code := "ok", http.StatusOK
+		for _, res := range results {
+			if res.Status == "degraded" {
+				status = "degraded"
+			}
+		}
+		if !ok {
+			status, code = "fail", http.StatusServiceUnavailable
+		}
+		response.Json(w, map[string]any{
+			"status": status,
+			"checks": results,
+		}, code)
+	})
+}
+
+// LiveHandler отвечает 200, пока процесс способен обрабатывать запросы.
+// Зависимости здесь намеренно не проверяются: их недоступность не повод перезапускать под.
+func LiveHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response.Json(w, map[string]string{"status": "ok"}, http.StatusOK)
+	})
+}
@@ -0,0 +1,51 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadyHandler(t *testing.T) {
+	var calls atomic.Int32
+	checker := NewChecker(time.Minute,
+		Check{Name: "ok", Timeout: time.Second, Func: func(ctx context.Context) error {
+			calls.Add(1)
+			return nil
+		}},
+		Check{Name: "slow", Timeout: 10 * time.Millisecond, Func: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}},
+		Check{Name: "broken", Timeout: time.Second, Func: func(ctx context.Context) error {
+			return errors.New("connection refused")
+		}},
+	)
+
+	rec := httptest.NewRecorder()
+	checker.ReadyHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	var body struct {
+		Status string            `json:"status"`
+		Checks map[string]Result `json:"checks"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "fail", body.Status)
+	assert.Equal(t, "ok", body.Checks["ok"].Status)
+	assert.Equal(t, "fail", body.Checks["slow"].Status)
+	assert.Contains(t, body.Checks["slow"].Error, "deadline exceeded")
+	assert.Equal(t, "connection refused", body.Checks["broken"].Error)
+
+	// Повторный запрос в пределах ttl берет результаты из кеша
+	checker.Run(context.Background())
+	assert.Equal(t, int32(1), calls.Load())
+}
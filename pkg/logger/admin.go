@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/SteepTaq/todo_project/pkg/response"
+)
+
+// LevelHandler - служебный эндпоинт уровня логирования.
+// GET возвращает текущий уровень, PUT с телом {"level": "debug"} меняет его.
+// Запрос должен содержать заголовок "Authorization: Bearer <token>";
+// с пустым token эндпоинт отключен.
+func LevelHandler(token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token == "" {
+			http.NotFound(w, r)
+			return
+		}
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			response.Json(w, map[string]string{"error": "unauthorized"}, http.StatusUnauthorized)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			var body struct {
+				Level string `json:"level"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				response.Json(w, map[string]string{"error": "invalid request format"}, http.StatusBadRequest)
+				return
+			}
+			if err := SetLevel(body.Level); err != nil {
+				response.Json(w, map[string]string{"error": err.Error()}, http.StatusBadRequest)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			response.Json(w, map[string]string{"error": "method not allowed"}, http.StatusMethodNotAllowed)
+			return
+		}
+
+		response.Json(w, map[string]string{"level": Level()}, http.StatusOK)
+	})
+}
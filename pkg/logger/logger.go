@@ -1,31 +1,142 @@
 package logger
 
 import (
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/SteepTaq/todo_project/pkg/conf"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-func Setup(level string) *slog.Logger {
-	var logLevel slog.Level
-	switch level {
-	case "debug":
-		logLevel = slog.LevelDebug
-	case "warn":
-		logLevel = slog.LevelWarn
-	case "error":
-		logLevel = slog.LevelError
-	case "info":
-		logLevel = slog.LevelInfo
-	default:
+type Config struct {
+	Level   string   `mapstructure:"level"`
+	Format  string   `mapstructure:"format"` // text | json
+	Outputs []Output `mapstructure:"outputs"`
+}
+
+type Output struct {
+	Type       string `mapstructure:"type"` // stdout | stderr | file
+	Path       string `mapstructure:"path"`
+	MaxSizeMB  int    `mapstructure:"max_size_mb"`
+	MaxBackups int    `mapstructure:"max_backups"`
+	MaxAgeDays int    `mapstructure:"max_age_days"`
+	Compress   bool   `mapstructure:"compress"`
+}
+
+// level - текущий уровень логирования процесса, его можно менять на лету через SetLevel
+var level = new(slog.LevelVar)
+
+func Setup(cfg Config) (*slog.Logger, error) {
+	logLevel, err := parseLevel(cfg.Level)
+	if err != nil {
 		logLevel = slog.LevelInfo
 	}
+	level.Set(logLevel)
+
+	w, err := newWriter(cfg.Outputs)
+	if err != nil {
+		return nil, err
+	}
 
 	opts := &slog.HandlerOptions{
-		Level: logLevel,
+		Level: level,
+	}
+
+	var handler slog.Handler
+	switch cfg.Format {
+	case "json":
+		handler = slog.NewJSONHandler(w, opts)
+	case "text", "":
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("unknown log format %q", cfg.Format)
+	}
+
+	return slog.New(handler), nil
+}
+
+// SetLevel меняет уровень логирования без перезапуска
+func SetLevel(s string) error {
+	l, err := parseLevel(s)
+	if err != nil {
+		return err
 	}
+	level.Set(l)
+	return nil
+}
+
+func Level() string {
+	return strings.ToLower(level.Level().String())
+}
 
-	handler := slog.NewTextHandler(os.Stdout, opts)
-	//	handler := slog.NewJSONHandler(os.Stdout, opts)
+func parseLevel(s string) (slog.Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(s)); err != nil {
+		return slog.LevelInfo, fmt.Errorf("unknown log level %q", s)
+	}
+	return l, nil
+}
+
+func newWriter(outputs []Output) (io.Writer, error) {
+	if len(outputs) == 0 {
+		return os.Stdout, nil
+	}
+
+	writers := make([]io.Writer, 0, len(outputs))
+	for _, out := range outputs {
+		switch out.Type {
+		case "stdout":
+			writers = append(writers, os.Stdout)
+		case "stderr":
+			writers = append(writers, os.Stderr)
+		case "file":
+			if out.Path == "" {
+				return nil, fmt.Errorf("log output of type file requires a path")
+			}
+			if err := os.MkdirAll(filepath.Dir(out.Path), 0o755); err != nil {
+				return nil, fmt.Errorf("failed to create log directory: %w", err)
+			}
+			writers = append(writers, &lumberjack.Logger{
+				Filename:   out.Path,
+				MaxSize:    out.MaxSizeMB,
+				MaxBackups: out.MaxBackups,
+				MaxAge:     out.MaxAgeDays,
+				Compress:   out.Compress,
+			})
+		default:
+			return nil, fmt.Errorf("unknown log output %q", out.Type)
+		}
+	}
 
-	return slog.New(handler)
+	if len(writers) == 1 {
+		return writers[0], nil
+	}
+	return io.MultiWriter(writers...), nil
+}
+
+// Defaults - значения по умолчанию для конфигурации
+func Defaults() map[string]any {
+	return map[string]any{
+		"level":   "info",
+		"format":  "text",
+		"outputs": []map[string]any{{"type": "stdout"}},
+	}
+}
+
+func (c Config) Validate(v *conf.Validator) {
+	if _, err := parseLevel(c.Level); err != nil {
+		v.Errorf("level", "unknown log level %q", c.Level)
+	}
+	v.OneOf("format", c.Format, "text", "json")
+	for i, out := range c.Outputs {
+		field := fmt.Sprintf("outputs[%d]", i)
+		v.OneOf(field+".type", out.Type, "stdout", "stderr", "file")
+		if out.Type == "file" {
+			v.Required(field+".path", out.Path)
+		}
+	}
 }
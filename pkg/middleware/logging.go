@@ -1 +1,112 @@
 package middleware
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	ctxLog "github.com/SteepTaq/todo_project/pkg/context"
+	"github.com/go-chi/chi/v5"
+	chimw "github.com/go-chi/chi/v5/middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type AccessLogConfig struct {
+	// Доля успешных запросов, которые попадают в лог: 1 - все, 0 - ни одного.
+	// Ошибки пишутся всегда.
+	SampleRate float64 `mapstructure:"sample_rate"`
+}
+
+func (c AccessLogConfig) sampled() bool {
+	return c.SampleRate >= 1 || rand.Float64() < c.SampleRate
+}
+
+// AccessLog пишет строку access-лога на каждый HTTP запрос.
+// request_id и пользователь берутся из ctxLog.RequestInfo, поэтому middleware
+// нужно подключать после того, который кладет их в контекст.
+func AccessLog(log *slog.Logger, cfg AccessLogConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ww := chimw.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			level := slog.LevelInfo
+			switch {
+			case status >= http.StatusInternalServerError:
+				level = slog.LevelError
+			case status >= http.StatusBadRequest:
+				level = slog.LevelWarn
+			case !cfg.sampled():
+				return
+			}
+
+			route := ""
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				route = rctx.RoutePattern()
+			}
+			info := ctxLog.RequestInfoFromContext(r.Context())
+			log.Log(r.Context(), level, "http request",
+				"method", r.Method,
+				"route", route,
+				"status", status,
+				"bytes", ww.BytesWritten(),
+				"latency", time.Since(start),
+				"request_id", info.RequestID,
+				"user_id", info.UserID,
+				"remote_ip", r.RemoteAddr,
+			)
+		})
+	}
+}
+
+// UnaryServerAccessLog - то же для unary вызовов gRPC. Подключается после UnaryServerRequestInfo.
+func UnaryServerAccessLog(log *slog.Logger, cfg AccessLogConfig) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		level := slog.LevelInfo
+		switch code {
+		case codes.OK:
+			if !cfg.sampled() {
+				return resp, err
+			}
+		case codes.NotFound, codes.InvalidArgument, codes.AlreadyExists, codes.Canceled:
+			level = slog.LevelWarn
+		default:
+			level = slog.LevelError
+		}
+
+		remote := ""
+		if p, ok := peer.FromContext(ctx); ok {
+			remote = p.Addr.String()
+		}
+		reqInfo := ctxLog.RequestInfoFromContext(ctx)
+		attrs := []any{
+			"method", info.FullMethod,
+			"code", code.String(),
+			"latency", time.Since(start),
+			"request_id", reqInfo.RequestID,
+			"user_id", reqInfo.UserID,
+			"remote_ip", remote,
+		}
+		if err != nil {
+			attrs = append(attrs, "error", err)
+		}
+		log.Log(ctx, level, "grpc request", attrs...)
+
+		return resp, err
+	}
+}
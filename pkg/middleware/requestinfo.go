@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+
+	ctxLog "github.com/SteepTaq/todo_project/pkg/context"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Ключи метаданных gRPC, в которых передаются сведения о запросе
+const (
+	RequestIDKey = "x-request-id"
+	UserIDKey    = "x-user-id"
+	TenantIDKey  = "x-tenant-id"
+)
+
+// UnaryClientRequestInfo передает request ID, пользователя и тенанта из контекста в метаданных вызова
+func UnaryClientRequestInfo() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoingRequestInfo(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+func StreamClientRequestInfo() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoingRequestInfo(ctx), desc, cc, method, opts...)
+	}
+}
+
+// UnaryServerRequestInfo восстанавливает сведения о запросе из метаданных и кладет
+// в контекст логгер с request_id, user_id и tenant_id. Если клиент не передал
+// request ID, генерируется новый.
+func UnaryServerRequestInfo(log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(incomingRequestInfo(ctx, log, info.FullMethod), req)
+	}
+}
+
+func StreamServerRequestInfo(log *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &contextStream{
+			ServerStream: ss,
+			ctx:          incomingRequestInfo(ss.Context(), log, info.FullMethod),
+		})
+	}
+}
+
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+func outgoingRequestInfo(ctx context.Context) context.Context {
+	info := ctxLog.RequestInfoFromContext(ctx)
+
+	var pairs []string
+	if info.RequestID != "" {
+		pairs = append(pairs, RequestIDKey, info.RequestID)
+	}
+	if info.UserID != "" {
+		pairs = append(pairs, UserIDKey, info.UserID)
+	}
+	if info.TenantID != "" {
+		pairs = append(pairs, TenantIDKey, info.TenantID)
+	}
+	if len(pairs) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+func incomingRequestInfo(ctx context.Context, log *slog.Logger, method string) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	info := ctxLog.RequestInfo{
+		RequestID: first(RequestIDKey),
+		UserID:    first(UserIDKey),
+		TenantID:  first(TenantIDKey),
+	}
+	if info.RequestID == "" {
+		info.RequestID = uuid.NewString()
+	}
+
+	ctx = ctxLog.WithRequestInfo(ctx, info)
+	return ctxLog.WithLogger(ctx, log.With(info.Attrs()...).With("grpc_method", method))
+}
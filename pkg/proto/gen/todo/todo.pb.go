@@ -22,6 +22,58 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type TaskEventType int32
+
+const (
+	TaskEventType_TASK_EVENT_TYPE_UNSPECIFIED TaskEventType = 0
+	TaskEventType_TASK_EVENT_TYPE_CREATED     TaskEventType = 1
+	TaskEventType_TASK_EVENT_TYPE_UPDATED     TaskEventType = 2
+	TaskEventType_TASK_EVENT_TYPE_DELETED     TaskEventType = 3
+)
+
+// Enum value maps for TaskEventType.
+var (
+	TaskEventType_name = map[int32]string{
+		0: "TASK_EVENT_TYPE_UNSPECIFIED",
+		1: "TASK_EVENT_TYPE_CREATED",
+		2: "TASK_EVENT_TYPE_UPDATED",
+		3: "TASK_EVENT_TYPE_DELETED",
+	}
+	TaskEventType_value = map[string]int32{
+		"TASK_EVENT_TYPE_UNSPECIFIED": 0,
+		"TASK_EVENT_TYPE_CREATED":     1,
+		"TASK_EVENT_TYPE_UPDATED":     2,
+		"TASK_EVENT_TYPE_DELETED":     3,
+	}
+)
+
+func (x TaskEventType) Enum() *TaskEventType {
+	p := new(TaskEventType)
+	*p = x
+	return p
+}
+
+func (x TaskEventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TaskEventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_todo_todo_proto_enumTypes[0].Descriptor()
+}
+
+func (TaskEventType) Type() protoreflect.EnumType {
+	return &file_todo_todo_proto_enumTypes[0]
+}
+
+func (x TaskEventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TaskEventType.Descriptor instead.
+func (TaskEventType) EnumDescriptor() ([]byte, []int) {
+	return file_todo_todo_proto_rawDescGZIP(), []int{0}
+}
+
 type TaskStatus int32
 
 const (
@@ -55,11 +107,11 @@ func (x TaskStatus) String() string {
 }
 
 func (TaskStatus) Descriptor() protoreflect.EnumDescriptor {
-	return file_todo_todo_proto_enumTypes[0].Descriptor()
+	return file_todo_todo_proto_enumTypes[1].Descriptor()
 }
 
 func (TaskStatus) Type() protoreflect.EnumType {
-	return &file_todo_todo_proto_enumTypes[0]
+	return &file_todo_todo_proto_enumTypes[1]
 }
 
 func (x TaskStatus) Number() protoreflect.EnumNumber {
@@ -68,17 +120,23 @@ func (x TaskStatus) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use TaskStatus.Descriptor instead.
 func (TaskStatus) EnumDescriptor() ([]byte, []int) {
-	return file_todo_todo_proto_rawDescGZIP(), []int{0}
+	return file_todo_todo_proto_rawDescGZIP(), []int{1}
 }
 
 type Task struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
-	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
-	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
-	Status        TaskStatus             `protobuf:"varint,4,opt,name=status,proto3,enum=todo.TaskStatus" json:"status,omitempty"`
-	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	TaskId      string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Title       string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Description string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Status      TaskStatus             `protobuf:"varint,4,opt,name=status,proto3,enum=todo.TaskStatus" json:"status,omitempty"`
+	CreatedAt   *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt   *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	// Исполнитель (email). Пустая строка - не назначен.
+	Assignee string `protobuf:"bytes,7,opt,name=assignee,proto3" json:"assignee,omitempty"`
+	// Срок выполнения, не задан - без срока
+	DueAt *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=due_at,json=dueAt,proto3" json:"due_at,omitempty"`
+	// Проект, к которому относится задача. Пустая строка - без проекта.
+	Project       string `protobuf:"bytes,9,opt,name=project,proto3" json:"project,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -155,8 +213,32 @@ func (x *Task) GetUpdatedAt() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *Task) GetAssignee() string {
+	if x != nil {
+		return x.Assignee
+	}
+	return ""
+}
+
+func (x *Task) GetDueAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DueAt
+	}
+	return nil
+}
+
+func (x *Task) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
 type GetAllTasksRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Пустые фильтры - все задачи
+	Statuses      []TaskStatus `protobuf:"varint,1,rep,packed,name=statuses,proto3,enum=todo.TaskStatus" json:"statuses,omitempty"`
+	TaskIds       []string     `protobuf:"bytes,2,rep,name=task_ids,json=taskIds,proto3" json:"task_ids,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -191,6 +273,20 @@ func (*GetAllTasksRequest) Descriptor() ([]byte, []int) {
 	return file_todo_todo_proto_rawDescGZIP(), []int{1}
 }
 
+func (x *GetAllTasksRequest) GetStatuses() []TaskStatus {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+func (x *GetAllTasksRequest) GetTaskIds() []string {
+	if x != nil {
+		return x.TaskIds
+	}
+	return nil
+}
+
 type GetAllTasksResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Tasks         []*Task                `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
@@ -235,6 +331,95 @@ func (x *GetAllTasksResponse) GetTasks() []*Task {
 	return nil
 }
 
+type StreamTasksRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Размер одной порции задач. 0 - значение по умолчанию на сервере.
+	PageSize      int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamTasksRequest) Reset() {
+	*x = StreamTasksRequest{}
+	mi := &file_todo_todo_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamTasksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamTasksRequest) ProtoMessage() {}
+
+func (x *StreamTasksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_todo_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamTasksRequest.ProtoReflect.Descriptor instead.
+func (*StreamTasksRequest) Descriptor() ([]byte, []int) {
+	return file_todo_todo_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *StreamTasksRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type StreamTasksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tasks         []*Task                `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamTasksResponse) Reset() {
+	*x = StreamTasksResponse{}
+	mi := &file_todo_todo_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamTasksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamTasksResponse) ProtoMessage() {}
+
+func (x *StreamTasksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_todo_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamTasksResponse.ProtoReflect.Descriptor instead.
+func (*StreamTasksResponse) Descriptor() ([]byte, []int) {
+	return file_todo_todo_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *StreamTasksResponse) GetTasks() []*Task {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
 type GetTaskRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -244,7 +429,7 @@ type GetTaskRequest struct {
 
 func (x *GetTaskRequest) Reset() {
 	*x = GetTaskRequest{}
-	mi := &file_todo_todo_proto_msgTypes[3]
+	mi := &file_todo_todo_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -256,7 +441,7 @@ func (x *GetTaskRequest) String() string {
 func (*GetTaskRequest) ProtoMessage() {}
 
 func (x *GetTaskRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_todo_todo_proto_msgTypes[3]
+	mi := &file_todo_todo_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -269,7 +454,7 @@ func (x *GetTaskRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetTaskRequest.ProtoReflect.Descriptor instead.
 func (*GetTaskRequest) Descriptor() ([]byte, []int) {
-	return file_todo_todo_proto_rawDescGZIP(), []int{3}
+	return file_todo_todo_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *GetTaskRequest) GetId() string {
@@ -288,7 +473,7 @@ type GetTaskResponse struct {
 
 func (x *GetTaskResponse) Reset() {
 	*x = GetTaskResponse{}
-	mi := &file_todo_todo_proto_msgTypes[4]
+	mi := &file_todo_todo_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -300,7 +485,7 @@ func (x *GetTaskResponse) String() string {
 func (*GetTaskResponse) ProtoMessage() {}
 
 func (x *GetTaskResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_todo_todo_proto_msgTypes[4]
+	mi := &file_todo_todo_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -313,7 +498,7 @@ func (x *GetTaskResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetTaskResponse.ProtoReflect.Descriptor instead.
 func (*GetTaskResponse) Descriptor() ([]byte, []int) {
-	return file_todo_todo_proto_rawDescGZIP(), []int{4}
+	return file_todo_todo_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *GetTaskResponse) GetTask() *Task {
@@ -332,7 +517,7 @@ type CreateTaskRequest struct {
 
 func (x *CreateTaskRequest) Reset() {
 	*x = CreateTaskRequest{}
-	mi := &file_todo_todo_proto_msgTypes[5]
+	mi := &file_todo_todo_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -344,7 +529,7 @@ func (x *CreateTaskRequest) String() string {
 func (*CreateTaskRequest) ProtoMessage() {}
 
 func (x *CreateTaskRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_todo_todo_proto_msgTypes[5]
+	mi := &file_todo_todo_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -357,7 +542,7 @@ func (x *CreateTaskRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateTaskRequest.ProtoReflect.Descriptor instead.
 func (*CreateTaskRequest) Descriptor() ([]byte, []int) {
-	return file_todo_todo_proto_rawDescGZIP(), []int{5}
+	return file_todo_todo_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *CreateTaskRequest) GetTask() *Task {
@@ -377,7 +562,7 @@ type CreateTaskResponse struct {
 
 func (x *CreateTaskResponse) Reset() {
 	*x = CreateTaskResponse{}
-	mi := &file_todo_todo_proto_msgTypes[6]
+	mi := &file_todo_todo_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -389,7 +574,7 @@ func (x *CreateTaskResponse) String() string {
 func (*CreateTaskResponse) ProtoMessage() {}
 
 func (x *CreateTaskResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_todo_todo_proto_msgTypes[6]
+	mi := &file_todo_todo_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -402,7 +587,7 @@ func (x *CreateTaskResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateTaskResponse.ProtoReflect.Descriptor instead.
 func (*CreateTaskResponse) Descriptor() ([]byte, []int) {
-	return file_todo_todo_proto_rawDescGZIP(), []int{6}
+	return file_todo_todo_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *CreateTaskResponse) GetSuccess() bool {
@@ -428,7 +613,7 @@ type UpdateTaskRequest struct {
 
 func (x *UpdateTaskRequest) Reset() {
 	*x = UpdateTaskRequest{}
-	mi := &file_todo_todo_proto_msgTypes[7]
+	mi := &file_todo_todo_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -440,7 +625,7 @@ func (x *UpdateTaskRequest) String() string {
 func (*UpdateTaskRequest) ProtoMessage() {}
 
 func (x *UpdateTaskRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_todo_todo_proto_msgTypes[7]
+	mi := &file_todo_todo_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -453,7 +638,7 @@ func (x *UpdateTaskRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateTaskRequest.ProtoReflect.Descriptor instead.
 func (*UpdateTaskRequest) Descriptor() ([]byte, []int) {
-	return file_todo_todo_proto_rawDescGZIP(), []int{7}
+	return file_todo_todo_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *UpdateTaskRequest) GetTask() *Task {
@@ -472,7 +657,7 @@ type UpdateTaskResponse struct {
 
 func (x *UpdateTaskResponse) Reset() {
 	*x = UpdateTaskResponse{}
-	mi := &file_todo_todo_proto_msgTypes[8]
+	mi := &file_todo_todo_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -484,7 +669,7 @@ func (x *UpdateTaskResponse) String() string {
 func (*UpdateTaskResponse) ProtoMessage() {}
 
 func (x *UpdateTaskResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_todo_todo_proto_msgTypes[8]
+	mi := &file_todo_todo_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -497,7 +682,7 @@ func (x *UpdateTaskResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateTaskResponse.ProtoReflect.Descriptor instead.
 func (*UpdateTaskResponse) Descriptor() ([]byte, []int) {
-	return file_todo_todo_proto_rawDescGZIP(), []int{8}
+	return file_todo_todo_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *UpdateTaskResponse) GetTask() *Task {
@@ -516,7 +701,7 @@ type DeleteTaskRequest struct {
 
 func (x *DeleteTaskRequest) Reset() {
 	*x = DeleteTaskRequest{}
-	mi := &file_todo_todo_proto_msgTypes[9]
+	mi := &file_todo_todo_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -528,7 +713,7 @@ func (x *DeleteTaskRequest) String() string {
 func (*DeleteTaskRequest) ProtoMessage() {}
 
 func (x *DeleteTaskRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_todo_todo_proto_msgTypes[9]
+	mi := &file_todo_todo_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -541,7 +726,7 @@ func (x *DeleteTaskRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteTaskRequest.ProtoReflect.Descriptor instead.
 func (*DeleteTaskRequest) Descriptor() ([]byte, []int) {
-	return file_todo_todo_proto_rawDescGZIP(), []int{9}
+	return file_todo_todo_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *DeleteTaskRequest) GetTaskId() string {
@@ -561,7 +746,7 @@ type DeleteTaskResponse struct {
 
 func (x *DeleteTaskResponse) Reset() {
 	*x = DeleteTaskResponse{}
-	mi := &file_todo_todo_proto_msgTypes[10]
+	mi := &file_todo_todo_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -573,7 +758,7 @@ func (x *DeleteTaskResponse) String() string {
 func (*DeleteTaskResponse) ProtoMessage() {}
 
 func (x *DeleteTaskResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_todo_todo_proto_msgTypes[10]
+	mi := &file_todo_todo_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -586,7 +771,7 @@ func (x *DeleteTaskResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteTaskResponse.ProtoReflect.Descriptor instead.
 func (*DeleteTaskResponse) Descriptor() ([]byte, []int) {
-	return file_todo_todo_proto_rawDescGZIP(), []int{10}
+	return file_todo_todo_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *DeleteTaskResponse) GetSuccess() bool {
@@ -603,11 +788,124 @@ func (x *DeleteTaskResponse) GetTaskId() string {
 	return ""
 }
 
+type WatchTasksRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Пустые фильтры - все события. События удаления проходят фильтр по статусу.
+	Statuses      []TaskStatus `protobuf:"varint,1,rep,packed,name=statuses,proto3,enum=todo.TaskStatus" json:"statuses,omitempty"`
+	TaskIds       []string     `protobuf:"bytes,2,rep,name=task_ids,json=taskIds,proto3" json:"task_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchTasksRequest) Reset() {
+	*x = WatchTasksRequest{}
+	mi := &file_todo_todo_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchTasksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchTasksRequest) ProtoMessage() {}
+
+func (x *WatchTasksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_todo_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchTasksRequest.ProtoReflect.Descriptor instead.
+func (*WatchTasksRequest) Descriptor() ([]byte, []int) {
+	return file_todo_todo_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *WatchTasksRequest) GetStatuses() []TaskStatus {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+func (x *WatchTasksRequest) GetTaskIds() []string {
+	if x != nil {
+		return x.TaskIds
+	}
+	return nil
+}
+
+type TaskEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          TaskEventType          `protobuf:"varint,1,opt,name=type,proto3,enum=todo.TaskEventType" json:"type,omitempty"`
+	Task          *Task                  `protobuf:"bytes,2,opt,name=task,proto3" json:"task,omitempty"`
+	OccurredAt    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskEvent) Reset() {
+	*x = TaskEvent{}
+	mi := &file_todo_todo_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskEvent) ProtoMessage() {}
+
+func (x *TaskEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_todo_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskEvent.ProtoReflect.Descriptor instead.
+func (*TaskEvent) Descriptor() ([]byte, []int) {
+	return file_todo_todo_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *TaskEvent) GetType() TaskEventType {
+	if x != nil {
+		return x.Type
+	}
+	return TaskEventType_TASK_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *TaskEvent) GetTask() *Task {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+func (x *TaskEvent) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
 var File_todo_todo_proto protoreflect.FileDescriptor
 
 const file_todo_todo_proto_rawDesc = "" +
 	"\n" +
-	"\x0ftodo/todo.proto\x12\x04todo\x1a\x1fgoogle/protobuf/timestamp.proto\"\xf7\x01\n" +
+	"\x0ftodo/todo.proto\x12\x04todo\x1a\x1fgoogle/protobuf/timestamp.proto\"\xe0\x02\n" +
 	"\x04Task\x12\x17\n" +
 	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x14\n" +
 	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
@@ -616,10 +914,20 @@ const file_todo_todo_proto_rawDesc = "" +
 	"\n" +
 	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
 	"\n" +
-	"updated_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\x14\n" +
-	"\x12GetAllTasksRequest\"7\n" +
+	"updated_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x1a\n" +
+	"\bassignee\x18\a \x01(\tR\bassignee\x121\n" +
+	"\x06due_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\x05dueAt\x12\x18\n" +
+	"\aproject\x18\t \x01(\tR\aproject\"]\n" +
+	"\x12GetAllTasksRequest\x12,\n" +
+	"\bstatuses\x18\x01 \x03(\x0e2\x10.todo.TaskStatusR\bstatuses\x12\x19\n" +
+	"\btask_ids\x18\x02 \x03(\tR\ataskIds\"7\n" +
 	"\x13GetAllTasksResponse\x12 \n" +
 	"\x05tasks\x18\x01 \x03(\v2\n" +
+	".todo.TaskR\x05tasks\"1\n" +
+	"\x12StreamTasksRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\"7\n" +
+	"\x13StreamTasksResponse\x12 \n" +
+	"\x05tasks\x18\x01 \x03(\v2\n" +
 	".todo.TaskR\x05tasks\" \n" +
 	"\x0eGetTaskRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\"1\n" +
@@ -643,12 +951,26 @@ const file_todo_todo_proto_rawDesc = "" +
 	"\atask_id\x18\x01 \x01(\tR\x06taskId\"G\n" +
 	"\x12DeleteTaskResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x17\n" +
-	"\atask_id\x18\x02 \x01(\tR\x06taskId*]\n" +
+	"\atask_id\x18\x02 \x01(\tR\x06taskId\"\\\n" +
+	"\x11WatchTasksRequest\x12,\n" +
+	"\bstatuses\x18\x01 \x03(\x0e2\x10.todo.TaskStatusR\bstatuses\x12\x19\n" +
+	"\btask_ids\x18\x02 \x03(\tR\ataskIds\"\x91\x01\n" +
+	"\tTaskEvent\x12'\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x13.todo.TaskEventTypeR\x04type\x12\x1e\n" +
+	"\x04task\x18\x02 \x01(\v2\n" +
+	".todo.TaskR\x04task\x12;\n" +
+	"\voccurred_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt*\x87\x01\n" +
+	"\rTaskEventType\x12\x1f\n" +
+	"\x1bTASK_EVENT_TYPE_UNSPECIFIED\x10\x00\x12\x1b\n" +
+	"\x17TASK_EVENT_TYPE_CREATED\x10\x01\x12\x1b\n" +
+	"\x17TASK_EVENT_TYPE_UPDATED\x10\x02\x12\x1b\n" +
+	"\x17TASK_EVENT_TYPE_DELETED\x10\x03*]\n" +
 	"\n" +
 	"TaskStatus\x12\x17\n" +
 	"\x13TASK_STATUS_PENDING\x10\x00\x12\x1b\n" +
 	"\x17TASK_STATUS_IN_PROGRESS\x10\x01\x12\x19\n" +
-	"\x15TASK_STATUS_COMPLETED\x10\x022\xcc\x02\n" +
+	"\x15TASK_STATUS_COMPLETED\x10\x022\xcc\x03\n" +
 	"\vTodoService\x126\n" +
 	"\aGetTask\x12\x14.todo.GetTaskRequest\x1a\x15.todo.GetTaskResponse\x12?\n" +
 	"\n" +
@@ -657,7 +979,10 @@ const file_todo_todo_proto_rawDesc = "" +
 	"UpdateTask\x12\x17.todo.UpdateTaskRequest\x1a\x18.todo.UpdateTaskResponse\x12?\n" +
 	"\n" +
 	"DeleteTask\x12\x17.todo.DeleteTaskRequest\x1a\x18.todo.DeleteTaskResponse\x12B\n" +
-	"\vGetAllTasks\x12\x18.todo.GetAllTasksRequest\x1a\x19.todo.GetAllTasksResponseB?Z=github.com/SteepTaq/todo_project/pkg/proto/gen/todo/v1;todov1b\x06proto3"
+	"\vGetAllTasks\x12\x18.todo.GetAllTasksRequest\x1a\x19.todo.GetAllTasksResponse\x128\n" +
+	"\n" +
+	"WatchTasks\x12\x17.todo.WatchTasksRequest\x1a\x0f.todo.TaskEvent0\x01\x12D\n" +
+	"\vStreamTasks\x12\x18.todo.StreamTasksRequest\x1a\x19.todo.StreamTasksResponse0\x01B?Z=github.com/SteepTaq/todo_project/pkg/proto/gen/todo/v1;todov1b\x06proto3"
 
 var (
 	file_todo_todo_proto_rawDescOnce sync.Once
@@ -671,48 +996,64 @@ func file_todo_todo_proto_rawDescGZIP() []byte {
 	return file_todo_todo_proto_rawDescData
 }
 
-var file_todo_todo_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_todo_todo_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_todo_todo_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_todo_todo_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
 var file_todo_todo_proto_goTypes = []any{
-	(TaskStatus)(0),               // 0: todo.TaskStatus
-	(*Task)(nil),                  // 1: todo.Task
-	(*GetAllTasksRequest)(nil),    // 2: todo.GetAllTasksRequest
-	(*GetAllTasksResponse)(nil),   // 3: todo.GetAllTasksResponse
-	(*GetTaskRequest)(nil),        // 4: todo.GetTaskRequest
-	(*GetTaskResponse)(nil),       // 5: todo.GetTaskResponse
-	(*CreateTaskRequest)(nil),     // 6: todo.CreateTaskRequest
-	(*CreateTaskResponse)(nil),    // 7: todo.CreateTaskResponse
-	(*UpdateTaskRequest)(nil),     // 8: todo.UpdateTaskRequest
-	(*UpdateTaskResponse)(nil),    // 9: todo.UpdateTaskResponse
-	(*DeleteTaskRequest)(nil),     // 10: todo.DeleteTaskRequest
-	(*DeleteTaskResponse)(nil),    // 11: todo.DeleteTaskResponse
-	(*timestamppb.Timestamp)(nil), // 12: google.protobuf.Timestamp
+	(TaskEventType)(0),            // 0: todo.TaskEventType
+	(TaskStatus)(0),               // 1: todo.TaskStatus
+	(*Task)(nil),                  // 2: todo.Task
+	(*GetAllTasksRequest)(nil),    // 3: todo.GetAllTasksRequest
+	(*GetAllTasksResponse)(nil),   // 4: todo.GetAllTasksResponse
+	(*StreamTasksRequest)(nil),    // 5: todo.StreamTasksRequest
+	(*StreamTasksResponse)(nil),   // 6: todo.StreamTasksResponse
+	(*GetTaskRequest)(nil),        // 7: todo.GetTaskRequest
+	(*GetTaskResponse)(nil),       // 8: todo.GetTaskResponse
+	(*CreateTaskRequest)(nil),     // 9: todo.CreateTaskRequest
+	(*CreateTaskResponse)(nil),    // 10: todo.CreateTaskResponse
+	(*UpdateTaskRequest)(nil),     // 11: todo.UpdateTaskRequest
+	(*UpdateTaskResponse)(nil),    // 12: todo.UpdateTaskResponse
+	(*DeleteTaskRequest)(nil),     // 13: todo.DeleteTaskRequest
+	(*DeleteTaskResponse)(nil),    // 14: todo.DeleteTaskResponse
+	(*WatchTasksRequest)(nil),     // 15: todo.WatchTasksRequest
+	(*TaskEvent)(nil),             // 16: todo.TaskEvent
+	(*timestamppb.Timestamp)(nil), // 17: google.protobuf.Timestamp
 }
 var file_todo_todo_proto_depIdxs = []int32{
-	0,  // 0: todo.Task.status:type_name -> todo.TaskStatus
-	12, // 1: todo.Task.created_at:type_name -> google.protobuf.Timestamp
-	12, // 2: todo.Task.updated_at:type_name -> google.protobuf.Timestamp
-	1,  // 3: todo.GetAllTasksResponse.tasks:type_name -> todo.Task
-	1,  // 4: todo.GetTaskResponse.task:type_name -> todo.Task
-	1,  // 5: todo.CreateTaskRequest.task:type_name -> todo.Task
-	1,  // 6: todo.CreateTaskResponse.task:type_name -> todo.Task
-	1,  // 7: todo.UpdateTaskRequest.task:type_name -> todo.Task
-	1,  // 8: todo.UpdateTaskResponse.task:type_name -> todo.Task
-	4,  // 9: todo.TodoService.GetTask:input_type -> todo.GetTaskRequest
-	6,  // 10: todo.TodoService.CreateTask:input_type -> todo.CreateTaskRequest
-	8,  // 11: todo.TodoService.UpdateTask:input_type -> todo.UpdateTaskRequest
-	10, // 12: todo.TodoService.DeleteTask:input_type -> todo.DeleteTaskRequest
-	2,  // 13: todo.TodoService.GetAllTasks:input_type -> todo.GetAllTasksRequest
-	5,  // 14: todo.TodoService.GetTask:output_type -> todo.GetTaskResponse
-	7,  // 15: todo.TodoService.CreateTask:output_type -> todo.CreateTaskResponse
-	9,  // 16: todo.TodoService.UpdateTask:output_type -> todo.UpdateTaskResponse
-	11, // 17: todo.TodoService.DeleteTask:output_type -> todo.DeleteTaskResponse
-	3,  // 18: todo.TodoService.GetAllTasks:output_type -> todo.GetAllTasksResponse
-	14, // [14:19] is the sub-list for method output_type
-	9,  // [9:14] is the sub-list for method input_type
-	9,  // [9:9] is the sub-list for extension type_name
-	9,  // [9:9] is the sub-list for extension extendee
-	0,  // [0:9] is the sub-list for field type_name
+	1,  // 0: todo.Task.status:type_name -> todo.TaskStatus
+	17, // 1: todo.Task.created_at:type_name -> google.protobuf.Timestamp
+	17, // 2: todo.Task.updated_at:type_name -> google.protobuf.Timestamp
+	17, // 3: todo.Task.due_at:type_name -> google.protobuf.Timestamp
+	1,  // 4: todo.GetAllTasksRequest.statuses:type_name -> todo.TaskStatus
+	2,  // 5: todo.GetAllTasksResponse.tasks:type_name -> todo.Task
+	2,  // 6: todo.StreamTasksResponse.tasks:type_name -> todo.Task
+	2,  // 7: todo.GetTaskResponse.task:type_name -> todo.Task
+	2,  // 8: todo.CreateTaskRequest.task:type_name -> todo.Task
+	2,  // 9: todo.CreateTaskResponse.task:type_name -> todo.Task
+	2,  // 10: todo.UpdateTaskRequest.task:type_name -> todo.Task
+	2,  // 11: todo.UpdateTaskResponse.task:type_name -> todo.Task
+	1,  // 12: todo.WatchTasksRequest.statuses:type_name -> todo.TaskStatus
+	0,  // 13: todo.TaskEvent.type:type_name -> todo.TaskEventType
+	2,  // 14: todo.TaskEvent.task:type_name -> todo.Task
+	17, // 15: todo.TaskEvent.occurred_at:type_name -> google.protobuf.Timestamp
+	7,  // 16: todo.TodoService.GetTask:input_type -> todo.GetTaskRequest
+	9,  // 17: todo.TodoService.CreateTask:input_type -> todo.CreateTaskRequest
+	11, // 18: todo.TodoService.UpdateTask:input_type -> todo.UpdateTaskRequest
+	13, // 19: todo.TodoService.DeleteTask:input_type -> todo.DeleteTaskRequest
+	3,  // 20: todo.TodoService.GetAllTasks:input_type -> todo.GetAllTasksRequest
+	15, // 21: todo.TodoService.WatchTasks:input_type -> todo.WatchTasksRequest
+	5,  // 22: todo.TodoService.StreamTasks:input_type -> todo.StreamTasksRequest
+	8,  // 23: todo.TodoService.GetTask:output_type -> todo.GetTaskResponse
+	10, // 24: todo.TodoService.CreateTask:output_type -> todo.CreateTaskResponse
+	12, // 25: todo.TodoService.UpdateTask:output_type -> todo.UpdateTaskResponse
+	14, // 26: todo.TodoService.DeleteTask:output_type -> todo.DeleteTaskResponse
+	4,  // 27: todo.TodoService.GetAllTasks:output_type -> todo.GetAllTasksResponse
+	16, // 28: todo.TodoService.WatchTasks:output_type -> todo.TaskEvent
+	6,  // 29: todo.TodoService.StreamTasks:output_type -> todo.StreamTasksResponse
+	23, // [23:30] is the sub-list for method output_type
+	16, // [16:23] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
 }
 
 func init() { file_todo_todo_proto_init() }
@@ -725,8 +1066,8 @@ func file_todo_todo_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_todo_todo_proto_rawDesc), len(file_todo_todo_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   11,
+			NumEnums:      2,
+			NumMessages:   15,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -0,0 +1,238 @@
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/SteepTaq/todo_project/pkg/conf"
+)
+
+type Config struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+	// CA для проверки собеседника. Пусто - системные корневые сертификаты.
+	CAFile string `mapstructure:"ca_file"`
+	// Сервер требует и проверяет сертификат клиента (mTLS)
+	ClientAuth bool `mapstructure:"client_auth"`
+	// Имя в сертификате сервера, если оно не совпадает с адресом подключения
+	ServerName string `mapstructure:"server_name"`
+	// Как часто проверять, не изменились ли файлы
+	ReloadInterval time.Duration `mapstructure:"reload_interval"`
+}
+
+// Reloader держит сертификат и CA из файлов и перечитывает их при изменении.
+// Соединения, открытые до перезагрузки, продолжают работать со старыми.
+type Reloader struct {
+	cfg Config
+	log *slog.Logger
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	roots   *x509.CertPool
+	modTime map[string]time.Time
+}
+
+func NewReloader(cfg Config, log *slog.Logger) (*Reloader, error) {
+	r := &Reloader{
+		cfg: cfg,
+		log: log.With("component", "tls"),
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Run проверяет файлы каждые ReloadInterval, пока не завершится контекст
+func (r *Reloader) Run(ctx context.Context) {
+	if r.cfg.ReloadInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(r.cfg.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.load(); err != nil {
+				// Файлы могут меняться не одновременно: оставляем старые и пробуем снова
+				r.log.Error("failed to reload certificates", "error", err)
+				continue
+			}
+			r.log.Info("certificates reloaded")
+		}
+	}
+}
+
+// ServerConfig - настройки TLS сервера. При ClientAuth сертификат клиента
+// проверяется по актуальному CA в VerifyConnection.
+func (r *Reloader) ServerConfig() *tls.Config {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return r.certificate()
+		},
+	}
+	if r.cfg.ClientAuth {
+		cfg.ClientAuth = tls.RequireAnyClientCert
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			return r.verify(cs, "", x509.ExtKeyUsageClientAuth)
+		}
+	}
+	return cfg
+}
+
+// ClientConfig - настройки TLS клиента. Стандартная проверка сервера заменена
+// на VerifyConnection, чтобы использовать CA, перечитанный после старта.
+func (r *Reloader) ClientConfig() *tls.Config {
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         r.cfg.ServerName,
+		InsecureSkipVerify: true,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			return r.verify(cs, cs.ServerName, x509.ExtKeyUsageServerAuth)
+		},
+	}
+	if r.cfg.CertFile != "" {
+		cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return r.certificate()
+		}
+	}
+	return cfg
+}
+
+func (r *Reloader) certificate() (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.cert == nil {
+		return nil, errors.New("no certificate configured")
+	}
+	return r.cert, nil
+}
+
+func (r *Reloader) verify(cs tls.ConnectionState, serverName string, usage x509.ExtKeyUsage) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("peer did not present a certificate")
+	}
+
+	r.mu.RLock()
+	roots := r.roots
+	r.mu.RUnlock()
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		DNSName:       serverName,
+		KeyUsages:     []x509.ExtKeyUsage{usage},
+	})
+	return err
+}
+
+func (r *Reloader) files() []string {
+	var files []string
+	for _, f := range []string{r.cfg.CertFile, r.cfg.KeyFile, r.cfg.CAFile} {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+func (r *Reloader) changed() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, f := range r.files() {
+		info, err := os.Stat(f)
+		if err != nil || !info.ModTime().Equal(r.modTime[f]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Reloader) load() error {
+	modTime := make(map[string]time.Time)
+	for _, f := range r.files() {
+		info, err := os.Stat(f)
+		if err != nil {
+			return err
+		}
+		modTime[f] = info.ModTime()
+	}
+
+	var cert *tls.Certificate
+	if r.cfg.CertFile != "" || r.cfg.KeyFile != "" {
+		c, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load key pair: %w", err)
+		}
+		cert = &c
+	}
+
+	var roots *x509.CertPool
+	if r.cfg.CAFile != "" {
+		pem, err := os.ReadFile(r.cfg.CAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read CA: %w", err)
+		}
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in %s", r.cfg.CAFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = cert
+	r.roots = roots
+	r.modTime = modTime
+	r.mu.Unlock()
+
+	return nil
+}
+
+// Defaults - значения по умолчанию для конфигурации
+func Defaults() map[string]any {
+	return map[string]any{
+		"enabled":         false,
+		"cert_file":       "",
+		"key_file":        "",
+		"ca_file":         "",
+		"client_auth":     false,
+		"server_name":     "",
+		"reload_interval": "1m",
+	}
+}
+
+// Validate проверяет настройки сервера (server - true) или клиента
+func (c Config) Validate(v *conf.Validator, server bool) {
+	if !c.Enabled {
+		return
+	}
+	if server || c.CertFile != "" || c.KeyFile != "" {
+		v.Required("cert_file", c.CertFile)
+		v.Required("key_file", c.KeyFile)
+	}
+	if server && c.ClientAuth {
+		v.Required("ca_file", c.CAFile)
+	}
+	v.Duration("reload_interval", c.ReloadInterval)
+}
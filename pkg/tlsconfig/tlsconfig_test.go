@@ -0,0 +1,157 @@
+package tlsconfig
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type authority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newAuthority(t *testing.T, name string) *authority {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &authority{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue выписывает сертификат и возвращает его и ключ в PEM
+func (a *authority) issue(t *testing.T, name string, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, a.cert, &key.PublicKey, a.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+// handshake поднимает TLS сервер и возвращает ошибку рукопожатия клиента
+func handshake(t *testing.T, server, client *tls.Config) error {
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", server)
+	require.NoError(t, err)
+	defer lis.Close()
+
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.(*tls.Conn).Handshake()
+		io.Copy(io.Discard, conn)
+	}()
+
+	conn, err := tls.Dial("tcp", lis.Addr().String(), client)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	// В TLS 1.3 отказ сервера в сертификате клиента виден только при чтении
+	conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	_, err = conn.Read(make([]byte, 1))
+	if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		return nil
+	}
+	return err
+}
+
+func TestMutualTLSWithReload(t *testing.T) {
+	dir := t.TempDir()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ca := newAuthority(t, "ca")
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	writeFile(t, path("ca.crt"), ca.pem)
+	cert, key := ca.issue(t, "db-service", x509.ExtKeyUsageServerAuth)
+	writeFile(t, path("server.crt"), cert)
+	writeFile(t, path("server.key"), key)
+	cert, key = ca.issue(t, "api", x509.ExtKeyUsageClientAuth)
+	writeFile(t, path("client.crt"), cert)
+	writeFile(t, path("client.key"), key)
+
+	server, err := NewReloader(Config{
+		CertFile: path("server.crt"), KeyFile: path("server.key"),
+		CAFile: path("ca.crt"), ClientAuth: true,
+		ReloadInterval: 10 * time.Millisecond,
+	}, log)
+	require.NoError(t, err)
+	client, err := NewReloader(Config{
+		CertFile: path("client.crt"), KeyFile: path("client.key"),
+		CAFile: path("ca.crt"), ServerName: "db-service",
+		ReloadInterval: 10 * time.Millisecond,
+	}, log)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go server.Run(ctx)
+	go client.Run(ctx)
+
+	require.NoError(t, handshake(t, server.ServerConfig(), client.ClientConfig()))
+
+	anonymous, err := NewReloader(Config{CAFile: path("ca.crt"), ServerName: "db-service"}, log)
+	require.NoError(t, err)
+	assert.Error(t, handshake(t, server.ServerConfig(), anonymous.ClientConfig()), "client without certificate")
+
+	// Ротация CA: обе стороны подхватывают новые файлы без перезапуска
+	newCA := newAuthority(t, "new-ca")
+	cert, key = newCA.issue(t, "db-service", x509.ExtKeyUsageServerAuth)
+	writeFile(t, path("server.crt"), cert)
+	writeFile(t, path("server.key"), key)
+	cert, key = newCA.issue(t, "api", x509.ExtKeyUsageClientAuth)
+	writeFile(t, path("client.crt"), cert)
+	writeFile(t, path("client.key"), key)
+	writeFile(t, path("ca.crt"), newCA.pem)
+
+	assert.Eventually(t, func() bool {
+		return handshake(t, server.ServerConfig(), client.ClientConfig()) == nil
+	}, 2*time.Second, 20*time.Millisecond)
+	assert.Error(t, handshake(t, server.ServerConfig(), anonymous.ClientConfig()))
+}
@@ -0,0 +1,37 @@
+package tracing
+
+import (
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaHeaders - propagation.TextMapCarrier поверх заголовков сообщения Kafka
+type KafkaHeaders struct {
+	Message *kafka.Message
+}
+
+func (c KafkaHeaders) Get(key string) string {
+	for _, h := range c.Message.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func (c KafkaHeaders) Set(key, value string) {
+	for i, h := range c.Message.Headers {
+		if h.Key == key {
+			c.Message.Headers[i].Value = []byte(value)
+			return
+		}
+	}
+	c.Message.Headers = append(c.Message.Headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (c KafkaHeaders) Keys() []string {
+	keys := make([]string, 0, len(c.Message.Headers))
+	for _, h := range c.Message.Headers {
+		keys = append(keys, h.Key)
+	}
+	return keys
+}